@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/thefishhat/tamago/client"
+	"github.com/thefishhat/tamago/server"
+)
+
+const diffUsage = "usage: cli diff <a.json> [b.json]"
+
+// runDiff prints the differences between two snapshot files,
+// or between a snapshot file and the live world if only one is given.
+func runDiff(c *client.Client, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New(diffUsage)
+	}
+
+	from, err := readSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+
+	var diff server.SnapshotDiff
+	if len(args) == 2 {
+		to, err := readSnapshot(args[1])
+		if err != nil {
+			return err
+		}
+		diff, err = server.DiffSnapshots(*from, *to)
+		if err != nil {
+			return err
+		}
+	} else {
+		response, err := c.DiffSnapshot(from, nil)
+		if err != nil {
+			return err
+		}
+		diff = *response
+	}
+
+	printDiff(os.Stdout, diff)
+	return nil
+}
+
+func printDiff(w io.Writer, diff server.SnapshotDiff) {
+	if diff.IsEmpty() {
+		fmt.Fprintln(w, "no differences")
+		return
+	}
+
+	for _, entity := range diff.Removed {
+		fmt.Fprintf(w, "- entity %s [%s]\n", entity.Id, strings.Join(entity.Archetype, ", "))
+	}
+	for _, entity := range diff.Added {
+		fmt.Fprintf(w, "+ entity %s [%s]\n", entity.Id, strings.Join(entity.Archetype, ", "))
+	}
+	for _, entity := range diff.Changed {
+		fmt.Fprintf(w, "~ entity %s\n", entity.Id)
+		for _, name := range entity.RemovedComponents {
+			fmt.Fprintf(w, "    - %s\n", name)
+		}
+		for _, name := range entity.AddedComponents {
+			fmt.Fprintf(w, "    + %s\n", name)
+		}
+		for _, field := range entity.Fields {
+			path := field.Component
+			if field.Field != "" {
+				path += "." + field.Field
+			}
+			switch field.Type {
+			case server.ChangeAdded:
+				fmt.Fprintf(w, "    + %s: %s\n", path, formatDiffValue(field.To))
+			case server.ChangeRemoved:
+				fmt.Fprintf(w, "    - %s: %s\n", path, formatDiffValue(field.From))
+			default:
+				fmt.Fprintf(w, "    ~ %s: %s -> %s\n", path, formatDiffValue(field.From), formatDiffValue(field.To))
+			}
+		}
+	}
+}
+
+func formatDiffValue(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
@@ -0,0 +1,89 @@
+package header
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/thefishhat/tamago/server"
+)
+
+// refreshInterval is how often the status of the game loop is fetched.
+const refreshInterval = time.Second
+
+var (
+	pausedStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("11")).Padding(0, 1)
+	runningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Padding(0, 1)
+)
+
+type Client interface {
+	GetLoop() (*server.LoopStatus, error)
+}
+
+// Model shows the status of the game loop in a line above the wrapped model.
+type Model struct {
+	model  tea.Model
+	client Client
+	// status is nil while the status of the game loop is unknown.
+	status *server.LoopStatus
+}
+
+type statusMsg struct {
+	status *server.LoopStatus
+}
+
+func New(client Client, model tea.Model) *Model {
+	return &Model{
+		model:  model,
+		client: client,
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.model.Init(), m.fetchStatus)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case statusMsg:
+		m.status = msg.status
+		return m, tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+			return m.fetchStatus()
+		})
+	case tea.WindowSizeMsg:
+		msg.Height--
+		var cmd tea.Cmd
+		m.model, cmd = m.model.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.model, cmd = m.model.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) View() string {
+	return m.statusLine() + "\n" + m.model.View()
+}
+
+func (m *Model) statusLine() string {
+	if m.status == nil {
+		return ""
+	}
+	if m.status.Paused {
+		return pausedStyle.Render(fmt.Sprintf("PAUSED at frame %d", m.status.Frame))
+	}
+	return runningStyle.Render(fmt.Sprintf("running, frame %d", m.status.Frame))
+}
+
+// fetchStatus fetches the status of the game loop.
+// Failures are not reported, as the game loop may not be controllable.
+func (m *Model) fetchStatus() tea.Msg {
+	status, err := m.client.GetLoop()
+	if err != nil {
+		return statusMsg{}
+	}
+	return statusMsg{status: status}
+}
@@ -0,0 +1,64 @@
+package header
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/thefishhat/tamago/server"
+)
+
+type fakeClient struct {
+	status *server.LoopStatus
+}
+
+func (c fakeClient) GetLoop() (*server.LoopStatus, error) {
+	if c.status == nil {
+		return nil, errors.New("not available")
+	}
+	return c.status, nil
+}
+
+type fakeModel struct {
+	height int
+}
+
+func (m fakeModel) Init() tea.Cmd { return nil }
+
+func (m fakeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m fakeModel) View() string { return "content" }
+
+func TestModel_ShowsPausedStatus(t *testing.T) {
+	m := New(fakeClient{status: &server.LoopStatus{Paused: true, Frame: 42}}, fakeModel{})
+
+	m.Update(m.fetchStatus())
+
+	line, content, _ := strings.Cut(m.View(), "\n")
+	assert.Contains(t, line, "PAUSED at frame 42")
+	assert.Equal(t, "content", content)
+}
+
+func TestModel_HidesUnknownStatus(t *testing.T) {
+	m := New(fakeClient{}, fakeModel{})
+
+	m.Update(m.fetchStatus())
+
+	assert.Equal(t, "\ncontent", m.View())
+}
+
+func TestModel_ReservesHeaderLine(t *testing.T) {
+	m := New(fakeClient{}, fakeModel{})
+
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	assert.Equal(t, 23, m.model.(fakeModel).height)
+}
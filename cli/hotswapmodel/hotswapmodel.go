@@ -31,12 +31,14 @@ func (m HotSwapModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 	case ModelSwapper:
-		m.swapWithHistory(msg.GetModel())
-		return m, nil
+		cmd := m.swapWithHistory(msg.GetModel())
+		return m, cmd
 	case SwitchToLastModel:
 		if len(m.modelStack) > 0 {
+			deactivate(m.activeModel)
 			m.activeModel = m.modelStack[len(m.modelStack)-1]
 			m.modelStack = m.modelStack[:len(m.modelStack)-1]
+			return m, m.activeModel.Init()
 		}
 		return m, nil
 	case tea.WindowSizeMsg:
@@ -60,21 +62,29 @@ func (m HotSwapModel) View() string {
 	return m.activeModel.View()
 }
 
-func (m *HotSwapModel) swap(model tea.Model) {
+func (m *HotSwapModel) swap(model tea.Model) tea.Cmd {
 	resizedModel, _ := model.Update(tea.WindowSizeMsg{
 		Width:  m.width,
 		Height: m.height,
 	})
 	m.activeModel = resizedModel
+	return m.activeModel.Init()
 }
 
 func (m *HotSwapModel) pushCurrentModel() {
 	if m.activeModel != nil {
+		deactivate(m.activeModel)
 		m.modelStack = append(m.modelStack, m.activeModel)
 	}
 }
 
-func (m *HotSwapModel) swapWithHistory(model tea.Model) {
+func (m *HotSwapModel) swapWithHistory(model tea.Model) tea.Cmd {
 	m.pushCurrentModel()
-	m.swap(model)
+	return m.swap(model)
+}
+
+func deactivate(model tea.Model) {
+	if d, ok := model.(Deactivator); ok {
+		d.Deactivate()
+	}
 }
@@ -9,3 +9,10 @@ type ModelSwapper interface {
 }
 
 type SwitchToLastModel struct{}
+
+// Deactivator is implemented by models that hold resources, such as subscriptions,
+// which should be released while the model is not active.
+// The model's Init is called again when it becomes active.
+type Deactivator interface {
+	Deactivate()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/thefishhat/tamago/client"
+	"github.com/thefishhat/tamago/server"
+)
+
+const loopUsage = "usage: cli loop [pause|resume|step [frames]]"
+
+// runLoop prints the status of the game loop, or pauses, resumes or steps it.
+func runLoop(c *client.Client, args []string) error {
+	var status *server.LoopStatus
+	var err error
+	switch {
+	case len(args) == 0:
+		status, err = c.GetLoop()
+	case len(args) == 1 && args[0] == "pause":
+		status, err = c.PauseLoop()
+	case len(args) == 1 && args[0] == "resume":
+		status, err = c.ResumeLoop()
+	case len(args) <= 2 && args[0] == "step":
+		frames := 1
+		if len(args) == 2 {
+			frames, err = strconv.Atoi(args[1])
+			if err != nil {
+				return errors.New(loopUsage)
+			}
+		}
+		status, err = c.StepLoop(frames)
+	default:
+		return errors.New(loopUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	state := "running"
+	if status.Paused {
+		state = "paused"
+	}
+	fmt.Printf("%s at frame %d\n", state, status.Frame)
+	return nil
+}
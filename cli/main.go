@@ -1,18 +1,30 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/thefishhat/tamago/cli/header"
 	"github.com/thefishhat/tamago/cli/hotswapmodel"
 	"github.com/thefishhat/tamago/cli/views/entities"
+	"github.com/thefishhat/tamago/cli/views/worlds"
 	"github.com/thefishhat/tamago/client"
 	"github.com/thefishhat/tamago/config"
+	"github.com/thefishhat/tamago/server"
 )
 
+// commands run without the interactive UI, e.g. `cli snapshot export scene.json`.
+var commands = map[string]func(c *client.Client, args []string) error{
+	"snapshot": runSnapshot,
+	"diff":     runDiff,
+	"loop":     runLoop,
+}
+
 func main() {
 	var f *os.File
 	var err error
@@ -26,13 +38,42 @@ func main() {
 	}
 
 	cfg := config.LoadConfig()
-	client := client.NewClient(cfg.Addr)
+	addr := cfg.Addr
+	if cfg.DiscoveryFile != "" {
+		if discovered, err := server.ReadDiscoveryFile(cfg.DiscoveryFile); err == nil {
+			addr = discovered
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Println("[error] reading discovery file:", err)
+		}
+	}
+	client := client.NewClient(addr)
+	client.Token = cfg.Token
+
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(client, os.Args[2:]); err != nil {
+				fmt.Println("[error]", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 
-	entities := entities.NewEntitiesModel(client)
-	hotswap := hotswapmodel.New(entities)
-	p := tea.NewProgram(hotswap, tea.WithAltScreen())
+	hotswap := hotswapmodel.New(initialModel(client))
+	p := tea.NewProgram(header.New(client, hotswap), tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
 		log.Fatal("running program:", err)
 	}
 }
+
+// initialModel lets the user pick a world if the server serves several of them, otherwise it shows the entities.
+func initialModel(c *client.Client) tea.Model {
+	response, err := c.ListWorlds()
+	if err != nil || len(response.Worlds) <= 1 {
+		return entities.NewEntitiesModel(c)
+	}
+	return worlds.NewWorldsModel(c, response.Worlds, func(world string) hotswapmodel.ModelSwapper {
+		return entities.Open(c.InWorld(world), world)
+	})
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/thefishhat/tamago/client"
+	"github.com/thefishhat/tamago/server"
+)
+
+const snapshotUsage = "usage: cli snapshot export|import <file>"
+
+// runSnapshot exports the world to a file or restores it from one.
+func runSnapshot(c *client.Client, args []string) error {
+	if len(args) != 2 {
+		return errors.New(snapshotUsage)
+	}
+
+	switch command, path := args[0], args[1]; command {
+	case "export":
+		snapshot, err := c.GetSnapshot()
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding snapshot: %w", err)
+		}
+		if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+			return fmt.Errorf("writing snapshot: %w", err)
+		}
+		fmt.Printf("exported %d entities to %s\n", len(snapshot.Entities), path)
+	case "import":
+		snapshot, err := readSnapshot(path)
+		if err != nil {
+			return err
+		}
+		response, err := c.RestoreSnapshot(snapshot)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported %d entities from %s\n", len(response.Entities), path)
+	default:
+		return errors.New(snapshotUsage)
+	}
+	return nil
+}
+
+func readSnapshot(path string) (*server.Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	var snapshot server.Snapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, fmt.Errorf("decoding snapshot %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,57 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thefishhat/tamago/server"
+)
+
+// Subscription relays events streamed by the server as [EventMsg]s.
+type Subscription struct {
+	events <-chan server.Event
+	cancel context.CancelFunc
+}
+
+// EventMsg carries an event received by a subscription.
+// Models should ignore messages of subscriptions they do not own.
+type EventMsg struct {
+	Subscription *Subscription
+	Event        server.Event
+}
+
+// New subscribes to server events using the given watch function, e.g. [client.Client.Watch].
+func New(watch func(ctx context.Context) (<-chan server.Event, error)) (*Subscription, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := watch(ctx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("watching events: %w", err)
+	}
+	return &Subscription{
+		events: events,
+		cancel: cancel,
+	}, nil
+}
+
+// Next returns a command waiting for the next event.
+// It must be issued again after each received [EventMsg].
+func (s *Subscription) Next() tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-s.events
+		if !ok {
+			return nil
+		}
+		return EventMsg{
+			Subscription: s,
+			Event:        event,
+		}
+	}
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.cancel()
+}
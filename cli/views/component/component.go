@@ -1,15 +1,21 @@
 package component
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/thefishhat/tamago/cli/hotswapmodel"
+	"github.com/thefishhat/tamago/cli/subscription"
 	"github.com/thefishhat/tamago/server"
 )
 
@@ -20,6 +26,10 @@ var (
 type Client interface {
 	GetComponent(entityID string, componentName string, fieldPath string) (*server.ComponentResponse, error)
 	SetComponent(entityID string, componentName string, fieldPath string, value interface{}) error
+	ModifyCollection(entityID string, componentName string, fieldPath string, req server.ModifyCollectionRequest) (*server.ComponentResponse, error)
+	Undo() (*server.HistoryEntry, error)
+	Redo() (*server.HistoryEntry, error)
+	Watch(ctx context.Context, entityID string, componentName string, fieldPath string) (<-chan server.Event, error)
 }
 
 type ComponentModel struct {
@@ -29,6 +39,13 @@ type ComponentModel struct {
 	componentType server.ComponentType
 	fieldPath     string
 	client        Client
+	sub           *subscription.Subscription
+	// field and fields describe the items, so that they keep their metadata when updated by events.
+	field  server.FieldInfo
+	fields map[string]server.FieldInfo
+	// prompt reads the item to add to a slice or map while adding is set.
+	prompt textinput.Model
+	adding bool
 }
 
 func NewComponentModel(client Client, entityID string, componentName string, fieldPath string) *ComponentModel {
@@ -38,38 +55,72 @@ func NewComponentModel(client Client, entityID string, componentName string, fie
 	}
 
 	items := formatComponentAsItems(response)
-	delegate := newItemDelegate(items)
+	delegate := newItemDelegate(items, response.Type, response.Field)
 	list := list.New(items, delegate, 0, 0)
 	list.Title = "Entities > Entity " + entityID + " > " + componentName
 	if fieldPath != "" {
 		list.Title += " : " + fieldPath
 	}
 
+	prompt := textinput.New()
+
 	return &ComponentModel{
 		list:          list,
 		entityID:      entityID,
 		componentName: componentName,
 		componentType: response.Type,
+		field:         response.Field,
+		fields:        response.Fields,
 		fieldPath:     fieldPath,
 		client:        client,
+		prompt:        prompt,
 	}
 }
 
+// Init subscribes to changes of the field, so its value stays up to date.
 func (m *ComponentModel) Init() tea.Cmd {
-	return nil
+	sub, err := subscription.New(func(ctx context.Context) (<-chan server.Event, error) {
+		return m.client.Watch(ctx, m.entityID, m.componentName, m.fieldPath)
+	})
+	if err != nil {
+		log.Println("subscribing to component:", err)
+		return nil
+	}
+	m.sub = sub
+	return sub.Next()
+}
+
+func (m *ComponentModel) Deactivate() {
+	if m.sub != nil {
+		m.sub.Close()
+		m.sub = nil
+	}
 }
 
 func (m *ComponentModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	selectedItem, ok := m.list.SelectedItem().(componentItem)
-	if !ok {
-		return m, nil
+	if msg, ok := msg.(subscription.EventMsg); ok {
+		if msg.Subscription != m.sub {
+			return m, nil
+		}
+		m.applyEvent(msg.Event)
+		return m, m.sub.Next()
 	}
 
-	if selectedItem.input.IsEditing() {
+	if m.adding {
+		return m.updatePrompt(msg)
+	}
+
+	// There is no selected item if the component is an empty slice or map.
+	selectedItem, hasSelection := m.list.SelectedItem().(componentItem)
+
+	if hasSelection && selectedItem.input.IsEditing() {
 		inputMsg := selectedItem.input.Update(msg)
 		if inputDone, ok := inputMsg.(inputDone); ok {
 			selectedItem.input.SetIsEditing(false)
-			err := m.setValue(inputDone.value)
+			value, err := parseInput(inputDone.value, selectedItem.info)
+			if err == nil {
+				err = m.setValue(value)
+			}
 			if err != nil {
 				selectedItem.errMsg.SetMsg(err.Error())
 			} else {
@@ -92,7 +143,19 @@ func (m *ComponentModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.reloadItems()
 				return nil
 			}
+		case "g":
+			// Otherwise, g moves to the start of the list.
+			if hasSelection {
+				if cmd := m.followLink(selectedItem); cmd != nil {
+					return m, cmd
+				}
+			}
 		case "enter":
+			if hasSelection {
+				if cmd := m.followLink(selectedItem); cmd != nil {
+					return m, cmd
+				}
+			}
 			return m, func() tea.Msg {
 				newFieldPath := constructFieldPath(m.list, m.componentType, m.fieldPath)
 				if newFieldPath == m.fieldPath {
@@ -100,17 +163,25 @@ func (m *ComponentModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return Open(m.client, m.entityID, m.componentName, newFieldPath)
 			}
+		case "a":
+			return m, m.startAdding()
+		case "x":
+			if hasSelection {
+				return m, m.deleteItem(selectedItem)
+			}
+		case "u":
+			return m, m.undo()
+		case "ctrl+r":
+			return m, m.redo()
 		case "e":
-			return m, func() tea.Msg {
-				if len(m.list.Items()) == 1 {
-					selectedItem.input.SetIsEditing(true)
-				}
-				return nil
+			if hasSelection && len(m.list.Items()) == 1 {
+				return m, m.edit(selectedItem)
 			}
+			return m, nil
 		}
 	case tea.WindowSizeMsg:
 		h, v := docStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.list.SetSize(msg.Width-h, msg.Height-v-1)
 	}
 
 	var cmd tea.Cmd
@@ -119,12 +190,185 @@ func (m *ComponentModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *ComponentModel) View() string {
-	return docStyle.Render(
-		m.list.View(),
-	)
+	view := m.list.View()
+	if m.adding {
+		view += "\n" + m.prompt.View()
+	}
+	return docStyle.Render(view)
+}
+
+// followLink opens the component of another entity that the item links to, if it is a link.
+func (m *ComponentModel) followLink(item componentItem) tea.Cmd {
+	link, ok := server.LinkOf(item.value)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		return Open(m.client, link.EntityId, link.Component, link.Path)
+	}
+}
+
+// startAdding prompts for an element to append to a slice, or a key and value to set in a map.
+func (m *ComponentModel) startAdding() tea.Cmd {
+	switch m.componentType {
+	case server.ComponentTypeSlice:
+		m.prompt.Prompt = "Append: "
+		m.prompt.Placeholder = "value"
+	case server.ComponentTypeMap:
+		m.prompt.Prompt = "Set: "
+		m.prompt.Placeholder = "key=value"
+	default:
+		return nil
+	}
+	if m.field.Locked {
+		return m.list.NewStatusMessage(errMsgStyle.Render("field is locked"))
+	}
+	m.adding = true
+	m.prompt.Reset()
+	return m.prompt.Focus()
+}
+
+func (m *ComponentModel) updatePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.adding = false
+			m.prompt.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			m.adding = false
+			m.prompt.Blur()
+			return m, m.addItem(m.prompt.Value())
+		}
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
+
+func (m *ComponentModel) addItem(input string) tea.Cmd {
+	if input == "" {
+		return nil
+	}
+
+	req := server.ModifyCollectionRequest{Op: server.OpAppend, Value: parseValue(input)}
+	if m.componentType == server.ComponentTypeMap {
+		key, value, ok := strings.Cut(input, "=")
+		if !ok {
+			return m.list.NewStatusMessage(errMsgStyle.Render("expected key=value"))
+		}
+		req = server.ModifyCollectionRequest{Op: server.OpSet, Key: strings.TrimSpace(key), Value: parseValue(value)}
+	}
+	return m.modifyCollection(req, "Added item")
+}
+
+// deleteItem removes the selected element of a slice, or the selected key of a map.
+func (m *ComponentModel) deleteItem(item componentItem) tea.Cmd {
+	if m.field.Locked {
+		return m.list.NewStatusMessage(errMsgStyle.Render("field is locked"))
+	}
+	switch m.componentType {
+	case server.ComponentTypeSlice:
+		index := m.list.Index()
+		return m.modifyCollection(server.ModifyCollectionRequest{Op: server.OpRemove, Index: &index}, "Deleted "+item.name)
+	case server.ComponentTypeMap:
+		return m.modifyCollection(server.ModifyCollectionRequest{Op: server.OpDelete, Key: item.name}, "Deleted "+item.name)
+	default:
+		return nil
+	}
+}
+
+func (m *ComponentModel) modifyCollection(req server.ModifyCollectionRequest, status string) tea.Cmd {
+	response, err := m.client.ModifyCollection(m.entityID, m.componentName, m.fieldPath, req)
+	if err != nil {
+		return m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+	}
+	m.setResponse(response)
+	return m.list.NewStatusMessage(status)
+}
+
+// parseValue parses the input as JSON, falling back to the raw string,
+// so that strings can be typed without quotes.
+func parseValue(input string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(input), &value); err != nil {
+		return input
+	}
+	return value
+}
+
+// edit starts editing the item, unless the server reports that it cannot be set or is locked.
+// Bools are toggled rather than typed.
+func (m *ComponentModel) edit(item componentItem) tea.Cmd {
+	if item.info.Locked {
+		return m.list.NewStatusMessage(errMsgStyle.Render("field is locked"))
+	}
+	if item.info.Type != "" && !item.info.Settable {
+		return m.list.NewStatusMessage(errMsgStyle.Render(item.info.Type + " field is not settable"))
+	}
+	if b, ok := item.value.(bool); ok {
+		if err := m.setValue(!b); err != nil {
+			return m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+		}
+		m.reloadItems()
+		return nil
+	}
+	item.input.SetIsEditing(true)
+	return nil
+}
+
+// parseInput parses the edited value for a field described by info, so that invalid input is
+// rejected before it is sent. Without info, the input is parsed by [parseValue].
+func parseInput(input string, info server.FieldInfo) (interface{}, error) {
+	if info.Nullable && (input == "nil" || input == "null") {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("%q is not a valid %s", input, info.Type)
+	switch info.Kind {
+	case "bool":
+		b, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, invalid
+		}
+		return b, nil
+	case "int", "int8", "int16", "int32", "int64":
+		n, err := strconv.ParseInt(input, 10, kindBits(info.Kind))
+		if err != nil {
+			// Durations are also accepted as strings, e.g. "1.5s".
+			if info.Type == "time.Duration" {
+				return input, nil
+			}
+			return nil, invalid
+		}
+		return n, nil
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		n, err := strconv.ParseUint(input, 10, kindBits(info.Kind))
+		if err != nil {
+			return nil, invalid
+		}
+		return n, nil
+	case "float32", "float64":
+		f, err := strconv.ParseFloat(input, kindBits(info.Kind))
+		if err != nil {
+			return nil, invalid
+		}
+		return f, nil
+	case "string":
+		return input, nil
+	default:
+		return parseValue(input), nil
+	}
 }
 
-func (m *ComponentModel) setValue(value string) error {
+// kindBits returns the size of a numeric kind, e.g. 16 for "int16", or 0 for "int" and "uint".
+func kindBits(kind string) int {
+	bits, _ := strconv.Atoi(strings.TrimLeft(kind, "abcdefghijklmnopqrstuvwxyz"))
+	return bits
+}
+
+func (m *ComponentModel) setValue(value interface{}) error {
 	err := m.client.SetComponent(m.entityID, m.componentName, m.fieldPath, value)
 	if err != nil {
 		return fmt.Errorf("setting component: %w", err)
@@ -132,13 +376,64 @@ func (m *ComponentModel) setValue(value string) error {
 	return nil
 }
 
+// undo reverts the last edit made through the editor, which is not necessarily an edit of this component.
+func (m *ComponentModel) undo() tea.Cmd {
+	entry, err := m.client.Undo()
+	if err != nil {
+		return m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+	}
+	m.reloadItems()
+	return m.list.NewStatusMessage("Undid " + formatHistoryEntry(entry))
+}
+
+func (m *ComponentModel) redo() tea.Cmd {
+	entry, err := m.client.Redo()
+	if err != nil {
+		return m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+	}
+	m.reloadItems()
+	return m.list.NewStatusMessage("Redid " + formatHistoryEntry(entry))
+}
+
+func formatHistoryEntry(entry *server.HistoryEntry) string {
+	path := entry.Component
+	if entry.Field != "" {
+		path += "." + entry.Field
+	}
+	return fmt.Sprintf("entity %s %s: %v -> %v", entry.EntityId, path, entry.From, entry.To)
+}
+
+func (m *ComponentModel) applyEvent(event server.Event) {
+	if event.Type != server.EventFieldChanged || event.EntityId != m.entityID {
+		return
+	}
+	// Do not replace the item being edited.
+	if selectedItem, ok := m.list.SelectedItem().(componentItem); ok && selectedItem.input.IsEditing() {
+		return
+	}
+
+	m.componentType = event.ValueType
+	m.list.SetItems(formatComponentAsItems(&server.ComponentResponse{
+		Value:  event.Value,
+		Type:   event.ValueType,
+		Field:  m.field,
+		Fields: m.fields,
+	}))
+}
+
 func (m *ComponentModel) reloadItems() {
 	response, err := m.client.GetComponent(m.entityID, m.componentName, m.fieldPath)
 	if err != nil {
 		log.Fatal("fetching component:", err)
 	}
-	items := formatComponentAsItems(response)
-	m.list.SetItems(items)
+	m.setResponse(response)
+}
+
+func (m *ComponentModel) setResponse(response *server.ComponentResponse) {
+	m.componentType = response.Type
+	m.field = response.Field
+	m.fields = response.Fields
+	m.list.SetItems(formatComponentAsItems(response))
 }
 
 func constructFieldPath(l list.Model, componentType server.ComponentType, currPath string) string {
@@ -151,6 +446,11 @@ func constructFieldPath(l list.Model, componentType server.ComponentType, currPa
 		return currPath
 	}
 
+	// References lead to the value they reference, which is encoded elsewhere.
+	if refPath, ok := server.RefPath(selectedItem.value); ok {
+		return refPath
+	}
+
 	switch componentType {
 	case server.ComponentTypePrimitive, server.ComponentTypeNil:
 		return currPath
@@ -162,6 +462,9 @@ func constructFieldPath(l list.Model, componentType server.ComponentType, currPa
 		}
 		return res + selectedItem.name
 
+	case server.ComponentTypeMap:
+		return server.IndexFieldPath(currPath, selectedItem.name)
+
 	case server.ComponentTypeSlice:
 		return currPath + "[" + strconv.Itoa(l.Index()) + "]"
 
@@ -174,22 +477,27 @@ func formatComponentAsItems(component *server.ComponentResponse) []list.Item {
 	var items []list.Item
 
 	switch component.Type {
-	case server.ComponentTypeObject:
+	case server.ComponentTypeObject, server.ComponentTypeMap:
 		var obj map[string]interface{}
 		obj = component.Value.(map[string]interface{})
-		for key, value := range obj {
-			items = append(items, newComponentItem(key, value))
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			items = append(items, newComponentItem(key, obj[key], component.Fields[key]))
 		}
 	case server.ComponentTypeSlice:
 		var arr []interface{}
 		arr = component.Value.([]interface{})
 		for i, value := range arr {
-			items = append(items, newComponentItem(fmt.Sprintf("[%d]", i), value))
+			items = append(items, newComponentItem(fmt.Sprintf("[%d]", i), value, component.Fields[strconv.Itoa(i)]))
 		}
 	case server.ComponentTypePrimitive:
-		items = append(items, newComponentItem("value", component.Value))
+		items = append(items, newComponentItem("value", component.Value, component.Field))
 	case server.ComponentTypeNil:
-		items = append(items, newComponentItem("value", ""))
+		items = append(items, newComponentItem("value", "", component.Field))
 	}
 
 	return items
@@ -1,6 +1,7 @@
 package component
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -50,7 +51,7 @@ func TestConstructFieldPath(t *testing.T) {
 				Type: server.ComponentTypeObject,
 			},
 			componentType: server.ComponentTypeObject,
-			selectedIndex: 1,
+			selectedIndex: 0,
 			currPath:      "PersistedPath",
 			expectedPath:  "PersistedPath.AnotherPath",
 		},
@@ -80,6 +81,31 @@ func TestConstructFieldPath(t *testing.T) {
 			currPath:      "PersistedPath[1]",
 			expectedPath:  "PersistedPath[1][1]",
 		},
+		{
+			component: server.ComponentResponse{
+				Value: map[string]interface{}{
+					"1": false,
+					"2": false,
+				},
+				Type: server.ComponentTypeMap,
+			},
+			componentType: server.ComponentTypeMap,
+			selectedIndex: 1,
+			currPath:      "PersistedPath",
+			expectedPath:  "PersistedPath[2]",
+		},
+		{
+			component: server.ComponentResponse{
+				Value: map[string]interface{}{
+					"OnGround": map[string]interface{}{server.RefKey: "Space.Objects[1]"},
+				},
+				Type: server.ComponentTypeObject,
+			},
+			componentType: server.ComponentTypeObject,
+			selectedIndex: 0,
+			currPath:      "PersistedPath",
+			expectedPath:  "Space.Objects[1]",
+		},
 		{
 			component: server.ComponentResponse{
 				Value: nil,
@@ -105,6 +131,68 @@ func TestConstructFieldPath(t *testing.T) {
 	}
 }
 
+func TestParseValue(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]interface{}{
+		`42`:            float64(42),
+		`true`:          true,
+		`"quoted"`:      "quoted",
+		`unquoted`:      "unquoted",
+		`{"Name": "a"}`: map[string]interface{}{"Name": "a"},
+	}
+
+	for input, expected := range testCases {
+		t.Run(input, func(t *testing.T) {
+			actual := parseValue(input)
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Expected %#v, got %#v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		info     server.FieldInfo
+		expected interface{}
+		err      string
+	}{
+		{input: "true", info: server.FieldInfo{Type: "bool", Kind: "bool"}, expected: true},
+		{input: "yes", info: server.FieldInfo{Type: "bool", Kind: "bool"}, err: `"yes" is not a valid bool`},
+		{input: "-3", info: server.FieldInfo{Type: "int", Kind: "int"}, expected: int64(-3)},
+		{input: "1.5", info: server.FieldInfo{Type: "int", Kind: "int"}, err: `"1.5" is not a valid int`},
+		{input: "300", info: server.FieldInfo{Type: "uint8", Kind: "uint8"}, err: `"300" is not a valid uint8`},
+		{input: "1.5", info: server.FieldInfo{Type: "float64", Kind: "float64"}, expected: 1.5},
+		{input: "1.5s", info: server.FieldInfo{Type: "time.Duration", Kind: "int64"}, expected: "1.5s"},
+		{input: "42", info: server.FieldInfo{Type: "string", Kind: "string"}, expected: "42"},
+		{input: "nil", info: server.FieldInfo{Type: "*image.Point", Kind: "ptr", Nullable: true}, expected: nil},
+		{input: `{"X": 1}`, info: server.FieldInfo{Type: "image.Point", Kind: "struct"}, expected: map[string]interface{}{"X": float64(1)}},
+		{input: "42", expected: float64(42)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.info.Type+" "+tc.input, func(t *testing.T) {
+			actual, err := parseInput(tc.input, tc.info)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
 func getListFromComponent(component *server.ComponentResponse) list.Model {
 	items := formatComponentAsItems(component)
 	return list.New(items, list.NewDefaultDelegate(), 0, 0)
@@ -6,6 +6,8 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thefishhat/tamago/server"
 )
 
 type itemDelegate struct {
@@ -46,6 +48,11 @@ type delegateKeyMap struct {
 	choose  key.Binding
 	edit    key.Binding
 	refresh key.Binding
+	undo    key.Binding
+	redo    key.Binding
+	add     key.Binding
+	remove  key.Binding
+	follow  key.Binding
 }
 
 func newDelegateKeyMap() *delegateKeyMap {
@@ -66,25 +73,63 @@ func newDelegateKeyMap() *delegateKeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("[r]", "refresh"),
 		),
+		add: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("[a]", "add item"),
+		),
+		remove: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("[x]", "delete item"),
+		),
+		follow: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("[g]", "go to referenced entity"),
+		),
+		undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("[u]", "undo"),
+		),
+		redo: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("[ctrl+r]", "redo"),
+		),
 	}
 }
 
-func newItemDelegate(items []list.Item) list.ItemDelegate {
+// newItemDelegate lists the actions available on the items. The edit actions are hidden when the server
+// reports the field as locked.
+func newItemDelegate(items []list.Item, componentType server.ComponentType, field server.FieldInfo) list.ItemDelegate {
 	keys := newDelegateKeyMap()
 	listDelegate := list.NewDefaultDelegate()
 	d := &itemDelegate{defaultDelegate: &listDelegate}
 
-	if len(items) == 0 {
-		return d
-	}
-
 	d.help = []key.Binding{}
 	if len(items) == 1 {
-		d.help = append(d.help, keys.edit)
-	} else {
+		if item, ok := items[0].(componentItem); !ok || !item.info.Locked {
+			d.help = append(d.help, keys.edit)
+		}
+	} else if len(items) > 1 {
 		d.help = append(d.help, keys.choose)
 	}
-	d.help = append(d.help, keys.refresh, keys.back)
+	if (componentType == server.ComponentTypeSlice || componentType == server.ComponentTypeMap) && !field.Locked {
+		d.help = append(d.help, keys.add, keys.remove)
+	}
+	if hasLink(items) {
+		d.help = append(d.help, keys.follow)
+	}
+	d.help = append(d.help, keys.refresh, keys.undo, keys.redo, keys.back)
 
 	return d
 }
+
+// hasLink reports whether any of the items links to another component, see [server.LinkOf].
+func hasLink(items []list.Item) bool {
+	for _, item := range items {
+		if item, ok := item.(componentItem); ok {
+			if _, ok := server.LinkOf(item.value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
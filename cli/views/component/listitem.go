@@ -1,20 +1,38 @@
 package component
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/thefishhat/tamago/server"
+)
 
 type componentItem struct {
 	name   string
 	value  interface{}
+	info   server.FieldInfo
 	input  *toggleInput
 	errMsg *errorMsg
 }
 
-func (i componentItem) Title() string       { return "Type: " + i.name }
+func (i componentItem) Title() string {
+	if i.info.Type == "" {
+		return "Type: " + i.name
+	}
+	title := "Type: " + i.name + " (" + i.info.Type + ")"
+	if i.info.Locked {
+		title += " (locked)"
+	}
+	return title
+}
 func (i componentItem) FilterValue() string { return i.name + fmt.Sprintf("%v", i.value) }
 func (i componentItem) Description() string {
 	var renderedItem string
 	if i.input.IsEditing() {
 		renderedItem = i.input.View()
+	} else if link, ok := server.LinkOf(i.value); ok {
+		renderedItem = "Link: " + link.String()
+	} else if refPath, ok := server.RefPath(i.value); ok {
+		renderedItem = "Reference: " + formatRefPath(refPath)
 	} else {
 		renderedItem = "Value: " + fmt.Sprintf("%v", i.value)
 	}
@@ -24,11 +42,19 @@ func (i componentItem) Description() string {
 	return renderedItem
 }
 
-func newComponentItem(name string, value interface{}) componentItem {
+func newComponentItem(name string, value interface{}, info server.FieldInfo) componentItem {
 	return componentItem{
 		name:   name,
 		value:  value,
+		info:   info,
 		input:  newToggleInput(value),
 		errMsg: newErrorMsg(),
 	}
 }
+
+func formatRefPath(path string) string {
+	if path == "" {
+		return "(component)"
+	}
+	return path
+}
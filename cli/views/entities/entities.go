@@ -1,28 +1,65 @@
 package entities
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/url"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/thefishhat/tamago/cli/hotswapmodel"
+	"github.com/thefishhat/tamago/cli/subscription"
 	"github.com/thefishhat/tamago/cli/views/entity"
 	"github.com/thefishhat/tamago/server"
 )
 
-var docStyle = lipgloss.NewStyle().Margin(1, 2)
+var (
+	docStyle    = lipgloss.NewStyle().Margin(1, 2)
+	errMsgStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
 
 type Client interface {
 	GetEntities() (*server.ListEntitiesResponse, error)
+	FilterEntities(filter server.EntityFilter) (*server.ListEntitiesResponse, error)
+	Query(filter server.QueryFilter) (*server.ListEntitiesResponse, error)
 	GetEntity(entityID string) (*server.GetEntityResponse, error)
+	CreateEntity(components []string, values map[string]map[string]interface{}) (*server.GetEntityResponse, error)
+	DeleteEntity(entityID string) error
+	AddComponent(entityID string, componentName string, values map[string]interface{}) (*server.GetEntityResponse, error)
+	RemoveComponent(entityID string, componentName string) (*server.GetEntityResponse, error)
 	GetComponent(entityID string, componentName string, fieldPath string) (*server.ComponentResponse, error)
+	GetComponentWithDepth(entityID string, componentName string, fieldPath string, depth int) (*server.ComponentResponse, error)
 	SetComponent(entityID string, componentName string, fieldPath string, value interface{}) error
+	ModifyCollection(entityID string, componentName string, fieldPath string, req server.ModifyCollectionRequest) (*server.ComponentResponse, error)
+	Undo() (*server.HistoryEntry, error)
+	Redo() (*server.HistoryEntry, error)
+	Watch(ctx context.Context, entityID string, componentName string, fieldPath string) (<-chan server.Event, error)
 }
 
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptCreate
+	promptFilter
+	promptQuery
+)
+
 type EntitiesModel struct {
 	list   list.Model
 	client Client
+	sub    *subscription.Subscription
+	filter server.EntityFilter
+	// query replaces filter while set.
+	query *server.QueryFilter
+	// prompt reads the input of the action selected by prompting.
+	prompt    textinput.Model
+	prompting promptKind
 }
 
 func NewEntitiesModel(client Client) *EntitiesModel {
@@ -40,23 +77,76 @@ func NewEntitiesModel(client Client) *EntitiesModel {
 	return &EntitiesModel{
 		list:   list,
 		client: client,
+		prompt: textinput.New(),
 	}
 }
 
+// Init subscribes to entity creation and removal, so the list stays up to date.
 func (m *EntitiesModel) Init() tea.Cmd {
-	return nil
+	sub, err := subscription.New(func(ctx context.Context) (<-chan server.Event, error) {
+		return m.client.Watch(ctx, "", "", "")
+	})
+	if err != nil {
+		log.Println("subscribing to entities:", err)
+		return nil
+	}
+	m.sub = sub
+	return sub.Next()
+}
+
+func (m *EntitiesModel) Deactivate() {
+	if m.sub != nil {
+		m.sub.Close()
+		m.sub = nil
+	}
 }
 
 func (m *EntitiesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.prompting != promptNone {
+		return m.updatePrompt(msg)
+	}
+
 	switch msg := msg.(type) {
+	case subscription.EventMsg:
+		if msg.Subscription != m.sub {
+			return m, nil
+		}
+		m.reloadItems()
+		return m, m.sub.Next()
 	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
+		case "esc":
+			// Escape clears the applied filter first, then goes back to the worlds, if the entities were opened from there.
+			if m.list.FilterState() == list.Unfiltered {
+				return m, func() tea.Msg { return hotswapmodel.SwitchToLastModel{} }
+			}
 		case "r":
-			response, _ := m.client.GetEntities()
-			items := formatEntitiesAsItems(response.Entities)
-			m.list.SetItems(items)
+			m.reloadItems()
+		case "n":
+			return m, m.openPrompt(promptCreate, "Components: ", "Object, Player", "")
+		case "f":
+			return m, m.openPrompt(promptFilter, "Filter: ", "tag=Player component=Object archetype=Object,Player", formatFilter(m.filter))
+		case "s":
+			var value string
+			if m.query != nil {
+				value = m.query.String()
+			}
+			return m, m.openPrompt(promptQuery, "Query: ", "and(Object, Tween, not(Player))", value)
+		case "x":
+			selected, ok := m.list.SelectedItem().(entitiesItem)
+			if !ok {
+				break
+			}
+			if err := m.client.DeleteEntity(selected.Id); err != nil {
+				return m, m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+			}
+			m.reloadItems()
+			return m, m.list.NewStatusMessage("Deleted entity " + selected.Id)
 		case "enter":
 			selected, ok := m.list.SelectedItem().(entitiesItem)
 			if !ok {
@@ -68,7 +158,7 @@ func (m *EntitiesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case tea.WindowSizeMsg:
 		h, v := docStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.list.SetSize(msg.Width-h, msg.Height-v-1)
 	}
 
 	var cmd tea.Cmd
@@ -77,7 +167,146 @@ func (m *EntitiesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *EntitiesModel) View() string {
-	return docStyle.Render(m.list.View())
+	view := m.list.View()
+	if m.prompting != promptNone {
+		view += "\n" + m.prompt.View()
+	}
+	return docStyle.Render(view)
+}
+
+func (m *EntitiesModel) openPrompt(kind promptKind, prompt, placeholder, value string) tea.Cmd {
+	m.prompting = kind
+	m.prompt.Prompt = prompt
+	m.prompt.Placeholder = placeholder
+	m.prompt.SetValue(value)
+	return m.prompt.Focus()
+}
+
+func (m *EntitiesModel) updatePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.prompting = promptNone
+			m.prompt.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			kind := m.prompting
+			m.prompting = promptNone
+			m.prompt.Blur()
+			switch kind {
+			case promptCreate:
+				return m, m.createEntity(m.prompt.Value())
+			case promptFilter:
+				return m, m.setFilter(m.prompt.Value())
+			case promptQuery:
+				return m, m.setQuery(m.prompt.Value())
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
+
+func (m *EntitiesModel) setFilter(input string) tea.Cmd {
+	filter, err := parseFilter(input)
+	if err != nil {
+		return m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+	}
+	m.filter = filter
+	m.query = nil
+	m.list.Title = "Entities"
+	if !filter.IsEmpty() {
+		m.list.Title += " [" + formatFilter(filter) + "]"
+	}
+	m.reloadItems()
+	return nil
+}
+
+// setQuery lists the entities matching the filter expression, or all entities if it is empty.
+func (m *EntitiesModel) setQuery(input string) tea.Cmd {
+	m.filter = server.EntityFilter{}
+	m.query = nil
+	m.list.Title = "Entities"
+	if strings.TrimSpace(input) != "" {
+		query, err := server.ParseQueryFilter(input)
+		if err != nil {
+			return m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+		}
+		m.query = &query
+		m.list.Title += " [" + query.String() + "]"
+	}
+	m.reloadItems()
+	return nil
+}
+
+func (m *EntitiesModel) createEntity(input string) tea.Cmd {
+	var components []string
+	for _, name := range strings.Split(input, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			components = append(components, name)
+		}
+	}
+	if len(components) == 0 {
+		return nil
+	}
+
+	response, err := m.client.CreateEntity(components, nil)
+	if err != nil {
+		return m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+	}
+	m.reloadItems()
+	return m.list.NewStatusMessage("Created entity " + response.Entity.Id)
+}
+
+func (m *EntitiesModel) reloadItems() {
+	var response *server.ListEntitiesResponse
+	var err error
+	if m.query != nil {
+		response, err = m.client.Query(*m.query)
+	} else {
+		response, err = m.client.FilterEntities(m.filter)
+	}
+	if err != nil {
+		log.Println("fetching entities:", err)
+		return
+	}
+	m.list.SetItems(formatEntitiesAsItems(response.Entities))
+}
+
+// parseFilter parses space-separated key=value conditions, e.g. "tag=Player component=Object".
+// Values can hold comma-separated names.
+func parseFilter(input string) (server.EntityFilter, error) {
+	query := url.Values{}
+	for _, condition := range strings.Fields(input) {
+		key, value, ok := strings.Cut(condition, "=")
+		if !ok {
+			return server.EntityFilter{}, fmt.Errorf("invalid condition %q, expected key=value", condition)
+		}
+		switch key {
+		case "tag", "component", "archetype":
+			query.Add(key, value)
+		default:
+			return server.EntityFilter{}, fmt.Errorf("unknown filter %q, expected tag, component or archetype", key)
+		}
+	}
+	return server.ParseEntityFilter(query), nil
+}
+
+func formatFilter(filter server.EntityFilter) string {
+	var conditions []string
+	for _, tag := range filter.Tags {
+		conditions = append(conditions, "tag="+tag)
+	}
+	for _, component := range filter.Components {
+		conditions = append(conditions, "component="+component)
+	}
+	if len(filter.Archetype) > 0 {
+		conditions = append(conditions, "archetype="+strings.Join(filter.Archetype, ","))
+	}
+	return strings.Join(conditions, " ")
 }
 
 func formatEntitiesAsItems(entities []server.EntitySummary) []list.Item {
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/thefishhat/tamago/server"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	filter, err := parseFilter("tag=Player component=Object,Tween archetype=Object,Player")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := server.EntityFilter{
+		Tags:       []string{"Player"},
+		Components: []string{"Object", "Tween"},
+		Archetype:  []string{"Object", "Player"},
+	}
+	if formatFilter(filter) != formatFilter(expected) {
+		t.Errorf("Expected %s, got %s", formatFilter(expected), formatFilter(filter))
+	}
+	if formatFilter(filter) != "tag=Player component=Object component=Tween archetype=Object,Player" {
+		t.Errorf("Unexpected formatted filter %s", formatFilter(filter))
+	}
+
+	for _, input := range []string{"Player", "name=Player"} {
+		if _, err := parseFilter(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
@@ -8,12 +8,20 @@ import (
 type delegateKeyMap struct {
 	choose  key.Binding
 	refresh key.Binding
+	create  key.Binding
+	remove  key.Binding
+	filter  key.Binding
+	query   key.Binding
 }
 
 func (d delegateKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		d.choose,
 		d.refresh,
+		d.create,
+		d.remove,
+		d.filter,
+		d.query,
 	}
 }
 
@@ -22,6 +30,10 @@ func (d delegateKeyMap) FullHelp() [][]key.Binding {
 		{
 			d.choose,
 			d.refresh,
+			d.create,
+			d.remove,
+			d.filter,
+			d.query,
 		},
 	}
 }
@@ -36,13 +48,29 @@ func newDelegateKeyMap() *delegateKeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("[r]", "refresh"),
 		),
+		create: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("[n]", "new"),
+		),
+		remove: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("[x]", "delete"),
+		),
+		filter: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("[f]", "filter"),
+		),
+		query: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("[s]", "query"),
+		),
 	}
 }
 
 func newItemDelegate() list.DefaultDelegate {
 	keys := newDelegateKeyMap()
 	d := list.NewDefaultDelegate()
-	help := []key.Binding{keys.choose, keys.refresh}
+	help := []key.Binding{keys.choose, keys.refresh, keys.create, keys.remove, keys.filter, keys.query}
 
 	d.ShortHelpFunc = func() []key.Binding {
 		return help
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thefishhat/tamago/cli/hotswapmodel"
+)
+
+type open struct {
+	World  string
+	Client Client
+}
+
+// Open opens the entities of the named world, which the client must access.
+func Open(client Client, world string) hotswapmodel.ModelSwapper {
+	return open{
+		World:  world,
+		Client: client,
+	}
+}
+
+func (msg open) GetModel() tea.Model {
+	m := NewEntitiesModel(msg.Client)
+	m.list.Title = "Entities (" + msg.World + ")"
+	return m
+}
@@ -1,11 +1,20 @@
 package entities
 
-import "github.com/thefishhat/tamago/server"
+import (
+	"strings"
+
+	"github.com/thefishhat/tamago/server"
+)
 
 type entitiesItem struct {
 	server.EntitySummary
 }
 
-func (i entitiesItem) Title() string       { return i.Id }
+func (i entitiesItem) Title() string {
+	if len(i.Tags) == 0 {
+		return i.Id
+	}
+	return i.Id + " #" + strings.Join(i.Tags, " #")
+}
 func (i entitiesItem) Description() string { return i.Name }
-func (i entitiesItem) FilterValue() string { return i.Name }
+func (i entitiesItem) FilterValue() string { return i.Name + strings.Join(i.Tags, "") }
@@ -1,29 +1,47 @@
 package entity
 
 import (
+	"context"
 	"log"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/thefishhat/tamago/cli/hotswapmodel"
+	"github.com/thefishhat/tamago/cli/subscription"
 	component "github.com/thefishhat/tamago/cli/views/component"
+	"github.com/thefishhat/tamago/cli/views/tree"
 	"github.com/thefishhat/tamago/server"
 )
 
-var docStyle = lipgloss.NewStyle().Margin(1, 2)
+var (
+	docStyle    = lipgloss.NewStyle().Margin(1, 2)
+	errMsgStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
 
 type Client interface {
 	GetEntity(entityID string) (*server.GetEntityResponse, error)
+	AddComponent(entityID string, componentName string, values map[string]interface{}) (*server.GetEntityResponse, error)
+	RemoveComponent(entityID string, componentName string) (*server.GetEntityResponse, error)
 	GetComponent(entityID string, componentName string, fieldPath string) (*server.ComponentResponse, error)
+	GetComponentWithDepth(entityID string, componentName string, fieldPath string, depth int) (*server.ComponentResponse, error)
 	SetComponent(entityID string, componentName string, fieldPath string, value interface{}) error
+	ModifyCollection(entityID string, componentName string, fieldPath string, req server.ModifyCollectionRequest) (*server.ComponentResponse, error)
+	Undo() (*server.HistoryEntry, error)
+	Redo() (*server.HistoryEntry, error)
+	Watch(ctx context.Context, entityID string, componentName string, fieldPath string) (<-chan server.Event, error)
 }
 
 type EntityModel struct {
 	list   list.Model
 	entity server.Entity
 	client Client
+	sub    *subscription.Subscription
+	// prompt reads the name of a component to add while adding is set.
+	prompt textinput.Model
+	adding bool
 }
 
 func NewEntityModel(client Client, entityID string) *EntityModel {
@@ -37,27 +55,78 @@ func NewEntityModel(client Client, entityID string) *EntityModel {
 	list := list.New(items, delegate, 0, 0)
 	list.Title = "Entities > Entity " + entityID
 
+	prompt := textinput.New()
+	prompt.Prompt = "Component: "
+	prompt.Placeholder = "Tween"
+
 	return &EntityModel{
 		list:   list,
 		entity: response.Entity,
 		client: client,
+		prompt: prompt,
 	}
 }
 
+// Init subscribes to changes of the entity's components, so their values stay up to date.
 func (m *EntityModel) Init() tea.Cmd {
-	return nil
+	sub, err := subscription.New(func(ctx context.Context) (<-chan server.Event, error) {
+		return m.client.Watch(ctx, m.entity.Id, "", "")
+	})
+	if err != nil {
+		log.Println("subscribing to entity:", err)
+		return nil
+	}
+	m.sub = sub
+	return sub.Next()
+}
+
+func (m *EntityModel) Deactivate() {
+	if m.sub != nil {
+		m.sub.Close()
+		m.sub = nil
+	}
 }
 
 func (m *EntityModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.adding {
+		return m.updatePrompt(msg)
+	}
+
 	switch msg := msg.(type) {
+	case subscription.EventMsg:
+		if msg.Subscription != m.sub {
+			return m, nil
+		}
+		m.applyEvent(msg.Event)
+		return m, m.sub.Next()
 	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
 		switch msg.String() {
 		case "esc":
 			return m, func() tea.Msg { return hotswapmodel.SwitchToLastModel{} }
 		case "r":
-			response, _ := m.client.GetEntity(m.entity.Id)
-			items := formatEntityAsItems(response.Entity)
-			m.list.SetItems(items)
+			response, err := m.client.GetEntity(m.entity.Id)
+			if err != nil {
+				return m, m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+			}
+			m.setEntity(response.Entity)
+		case "a":
+			m.adding = true
+			m.prompt.Reset()
+			return m, m.prompt.Focus()
+		case "x":
+			selected, ok := m.list.SelectedItem().(entityItem)
+			if !ok {
+				break
+			}
+			response, err := m.client.RemoveComponent(m.entity.Id, selected.Component.Name)
+			if err != nil {
+				return m, m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+			}
+			m.setEntity(response.Entity)
+			return m, m.list.NewStatusMessage("Removed " + selected.Component.Name)
 		case "enter":
 			selected, ok := m.list.SelectedItem().(entityItem)
 			if !ok {
@@ -66,10 +135,18 @@ func (m *EntityModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, func() tea.Msg {
 				return component.Open(m.client, m.entity.Id, selected.Component.Name, "")
 			}
+		case "t":
+			selected, ok := m.list.SelectedItem().(entityItem)
+			if !ok {
+				break
+			}
+			return m, func() tea.Msg {
+				return tree.Open(m.client, m.entity.Id, selected.Component.Name, "")
+			}
 		}
 	case tea.WindowSizeMsg:
 		h, v := docStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.list.SetSize(msg.Width-h, msg.Height-v-1)
 	}
 
 	var cmd tea.Cmd
@@ -78,9 +155,68 @@ func (m *EntityModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *EntityModel) View() string {
-	return docStyle.Render(
-		m.list.View(),
-	)
+	view := m.list.View()
+	if m.adding {
+		view += "\n" + m.prompt.View()
+	}
+	return docStyle.Render(view)
+}
+
+func (m *EntityModel) updatePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.adding = false
+			m.prompt.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			m.adding = false
+			m.prompt.Blur()
+			return m, m.addComponent(m.prompt.Value())
+		}
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
+
+func (m *EntityModel) addComponent(componentName string) tea.Cmd {
+	if componentName == "" {
+		return nil
+	}
+
+	response, err := m.client.AddComponent(m.entity.Id, componentName, nil)
+	if err != nil {
+		return m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+	}
+	m.setEntity(response.Entity)
+	return m.list.NewStatusMessage("Added " + componentName)
+}
+
+func (m *EntityModel) setEntity(entity server.Entity) {
+	m.entity = entity
+	m.list.SetItems(formatEntityAsItems(entity))
+}
+
+func (m *EntityModel) applyEvent(event server.Event) {
+	if event.EntityId != m.entity.Id {
+		return
+	}
+
+	switch event.Type {
+	case server.EventEntityRemoved:
+		m.list.Title = "Entities > Entity " + m.entity.Id + " (removed)"
+	case server.EventFieldChanged:
+		for i, item := range m.list.Items() {
+			item, ok := item.(entityItem)
+			if !ok || item.Name != event.Component {
+				continue
+			}
+			item.Value = event.Value
+			m.list.SetItem(i, item)
+		}
+	}
 }
 
 func formatEntityAsItems(entity server.Entity) []list.Item {
@@ -8,13 +8,19 @@ import (
 type delegateKeyMap struct {
 	back    key.Binding
 	choose  key.Binding
+	tree    key.Binding
 	refresh key.Binding
+	add     key.Binding
+	remove  key.Binding
 }
 
 func (d delegateKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		d.choose,
+		d.tree,
 		d.refresh,
+		d.add,
+		d.remove,
 	}
 }
 
@@ -22,7 +28,10 @@ func (d delegateKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{
 			d.choose,
+			d.tree,
 			d.refresh,
+			d.add,
+			d.remove,
 		},
 	}
 }
@@ -37,17 +46,29 @@ func newDelegateKeyMap() *delegateKeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("[enter]", "view"),
 		),
+		tree: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("[t]", "tree"),
+		),
 		refresh: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("[r]", "refresh"),
 		),
+		add: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("[a]", "add component"),
+		),
+		remove: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("[x]", "remove component"),
+		),
 	}
 }
 
 func newItemDelegate() list.DefaultDelegate {
 	keys := newDelegateKeyMap()
 	d := list.NewDefaultDelegate()
-	help := []key.Binding{keys.choose, keys.refresh, keys.back}
+	help := []key.Binding{keys.choose, keys.tree, keys.refresh, keys.add, keys.remove, keys.back}
 
 	d.ShortHelpFunc = func() []key.Binding {
 		return help
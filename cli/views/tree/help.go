@@ -0,0 +1,57 @@
+package tree
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+type delegateKeyMap struct {
+	back     key.Binding
+	toggle   key.Binding
+	expand   key.Binding
+	collapse key.Binding
+	refresh  key.Binding
+}
+
+func newDelegateKeyMap() *delegateKeyMap {
+	return &delegateKeyMap{
+		back: key.NewBinding(
+			key.WithKeys("escape"),
+			key.WithHelp("[esc]", "back"),
+		),
+		toggle: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("[enter]", "toggle/edit/follow"),
+		),
+		expand: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("[→]", "expand"),
+		),
+		collapse: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("[←]", "collapse"),
+		),
+		refresh: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("[r]", "refresh"),
+		),
+	}
+}
+
+// newItemDelegate renders each node on a single line, indented by its level.
+func newItemDelegate() list.DefaultDelegate {
+	keys := newDelegateKeyMap()
+	d := list.NewDefaultDelegate()
+	d.ShowDescription = false
+	d.SetSpacing(0)
+	help := []key.Binding{keys.toggle, keys.expand, keys.collapse, keys.refresh, keys.back}
+
+	d.ShortHelpFunc = func() []key.Binding {
+		return help
+	}
+
+	d.FullHelpFunc = func() [][]key.Binding {
+		return [][]key.Binding{help}
+	}
+	return d
+}
@@ -0,0 +1,21 @@
+package tree
+
+import "strings"
+
+type treeItem struct {
+	*node
+	expanded bool
+}
+
+func (i treeItem) Title() string {
+	marker := "  "
+	if !i.isLeaf() {
+		marker = "▸ "
+		if i.expanded {
+			marker = "▾ "
+		}
+	}
+	return strings.Repeat("  ", i.level) + marker + i.name + ": " + i.summary()
+}
+func (i treeItem) Description() string { return "" }
+func (i treeItem) FilterValue() string { return i.path }
@@ -0,0 +1,136 @@
+package tree
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/thefishhat/tamago/server"
+)
+
+// node is a field of a component, fetched in full so that it can be expanded without another request.
+type node struct {
+	name     string
+	path     string
+	value    interface{}
+	level    int
+	parent   *node
+	children []*node
+}
+
+func newNode(parent *node, name string, path string, value interface{}, level int) *node {
+	n := &node{
+		name:   name,
+		path:   path,
+		value:  value,
+		level:  level,
+		parent: parent,
+	}
+
+	if n.isRef() || n.isLink() {
+		return n
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			n.children = append(n.children, newNode(n, key, server.JoinFieldPath(path, key), value[key], level+1))
+		}
+	case []interface{}:
+		for i, elem := range value {
+			index := fmt.Sprintf("[%d]", i)
+			n.children = append(n.children, newNode(n, index, path+index, elem, level+1))
+		}
+	}
+	return n
+}
+
+// newRoot returns the node of the field at the path. Its children are the top-level rows of the tree,
+// a primitive field is shown as a single row.
+func newRoot(path string, value interface{}) *node {
+	root := newNode(nil, "", path, value, -1)
+	if root.isLeaf() {
+		root.children = []*node{newNode(root, "value", path, value, 0)}
+	}
+	return root
+}
+
+// isRef reports whether the node references a value encoded elsewhere, see [server.RefPath].
+func (n *node) isRef() bool {
+	_, ok := server.RefPath(n.value)
+	return ok
+}
+
+// isLink reports whether the node links to another component, see [server.LinkOf].
+func (n *node) isLink() bool {
+	_, ok := server.LinkOf(n.value)
+	return ok
+}
+
+// isLeaf reports whether the node is a primitive value, a reference or a link, rather than a struct, slice or map.
+func (n *node) isLeaf() bool {
+	if n.isRef() || n.isLink() {
+		return true
+	}
+	switch n.value.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	default:
+		return true
+	}
+}
+
+// rows returns the descendants of the node that are visible when the nodes with the expanded paths are expanded.
+func (n *node) rows(expanded map[string]bool) []*node {
+	var rows []*node
+	for _, child := range n.children {
+		rows = append(rows, child)
+		if expanded[child.path] {
+			rows = append(rows, child.rows(expanded)...)
+		}
+	}
+	return rows
+}
+
+// summary describes the value of the node, or its size if it has children.
+func (n *node) summary() string {
+	if link, ok := server.LinkOf(n.value); ok {
+		return "-> " + link.String()
+	}
+	if refPath, ok := server.RefPath(n.value); ok {
+		if refPath == "" {
+			return "-> (component)"
+		}
+		return "-> " + refPath
+	}
+	switch value := n.value.(type) {
+	case map[string]interface{}:
+		return fmt.Sprintf("{%d}", len(value))
+	case []interface{}:
+		return fmt.Sprintf("[%d]", len(value))
+	case string:
+		return strconv.Quote(value)
+	case nil:
+		return "nil"
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// find returns the node with the path among the node and its descendants.
+func (n *node) find(path string) *node {
+	if n.path == path {
+		return n
+	}
+	for _, child := range n.children {
+		if found := child.find(path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
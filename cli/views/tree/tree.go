@@ -0,0 +1,193 @@
+package tree
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/thefishhat/tamago/cli/hotswapmodel"
+	"github.com/thefishhat/tamago/cli/views/component"
+	"github.com/thefishhat/tamago/server"
+)
+
+var (
+	docStyle    = lipgloss.NewStyle().Margin(1, 2)
+	errMsgStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+type Client interface {
+	GetComponent(entityID string, componentName string, fieldPath string) (*server.ComponentResponse, error)
+	GetComponentWithDepth(entityID string, componentName string, fieldPath string, depth int) (*server.ComponentResponse, error)
+	SetComponent(entityID string, componentName string, fieldPath string, value interface{}) error
+	ModifyCollection(entityID string, componentName string, fieldPath string, req server.ModifyCollectionRequest) (*server.ComponentResponse, error)
+	Undo() (*server.HistoryEntry, error)
+	Redo() (*server.HistoryEntry, error)
+	Watch(ctx context.Context, entityID string, componentName string, fieldPath string) (<-chan server.Event, error)
+}
+
+// TreeModel shows a component as a tree of its fields. The component is fetched in full,
+// so nodes are expanded and collapsed in place without fetching them.
+type TreeModel struct {
+	list          list.Model
+	entityID      string
+	componentName string
+	fieldPath     string
+	client        Client
+	root          *node
+	// expanded holds the paths of the expanded nodes, so they stay expanded on refresh.
+	expanded map[string]bool
+}
+
+func NewTreeModel(client Client, entityID string, componentName string, fieldPath string) *TreeModel {
+	response, err := client.GetComponentWithDepth(entityID, componentName, fieldPath, server.FullDepth)
+	if err != nil {
+		log.Fatal("fetching component:", err)
+	}
+
+	list := list.New(nil, newItemDelegate(), 0, 0)
+	list.Title = "Entities > Entity " + entityID + " > " + componentName
+	if fieldPath != "" {
+		list.Title += " : " + fieldPath
+	}
+	list.SetFilteringEnabled(false)
+
+	m := &TreeModel{
+		list:          list,
+		entityID:      entityID,
+		componentName: componentName,
+		fieldPath:     fieldPath,
+		client:        client,
+		expanded:      make(map[string]bool),
+	}
+	m.setValue(response)
+	return m
+}
+
+func (m *TreeModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *TreeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		selected, hasSelection := m.list.SelectedItem().(treeItem)
+
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return hotswapmodel.SwitchToLastModel{} }
+		case "r":
+			response, err := m.client.GetComponentWithDepth(m.entityID, m.componentName, m.fieldPath, server.FullDepth)
+			if err != nil {
+				return m, m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+			}
+			m.setValue(response)
+			return m, nil
+		case "enter":
+			if !hasSelection {
+				break
+			}
+			if refPath, ok := server.RefPath(selected.value); ok {
+				return m, m.jump(refPath)
+			}
+			if link, ok := server.LinkOf(selected.value); ok {
+				return m, func() tea.Msg {
+					return component.Open(m.client, link.EntityId, link.Component, link.Path)
+				}
+			}
+			if selected.isLeaf() {
+				return m, func() tea.Msg {
+					return component.Open(m.client, m.entityID, m.componentName, selected.path)
+				}
+			}
+			m.expanded[selected.path] = !m.expanded[selected.path]
+			m.refreshRows()
+			return m, nil
+		case "right", "l":
+			if hasSelection && !selected.isLeaf() {
+				m.expanded[selected.path] = true
+				m.refreshRows()
+			}
+			return m, nil
+		case "left", "h":
+			if hasSelection {
+				m.collapse(selected.node)
+			}
+			return m, nil
+		}
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m *TreeModel) View() string {
+	return docStyle.Render(m.list.View())
+}
+
+// jump selects the node with the path, expanding its ancestors. Paths outside of the tree are opened in the component view.
+func (m *TreeModel) jump(path string) tea.Cmd {
+	target := m.root.find(path)
+	if target == nil || target == m.root {
+		return func() tea.Msg {
+			return component.Open(m.client, m.entityID, m.componentName, path)
+		}
+	}
+
+	for n := target.parent; n != nil && n != m.root; n = n.parent {
+		m.expanded[n.path] = true
+	}
+	m.refreshRows()
+	m.selectNode(target)
+	return nil
+}
+
+func (m *TreeModel) selectNode(n *node) {
+	for i, item := range m.list.Items() {
+		if item.(treeItem).node == n {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+// collapse collapses the node if it is expanded, otherwise its parent, which is then selected.
+func (m *TreeModel) collapse(n *node) {
+	if !m.expanded[n.path] {
+		n = n.parent
+		if n == nil || n == m.root {
+			return
+		}
+	}
+	delete(m.expanded, n.path)
+	m.refreshRows()
+	m.selectNode(n)
+}
+
+func (m *TreeModel) setValue(response *server.ComponentResponse) {
+	value := response.Value
+	// The server quotes string fields, but not the strings nested in them.
+	if str, ok := value.(string); ok && response.Type == server.ComponentTypePrimitive {
+		if unquoted, err := strconv.Unquote(str); err == nil {
+			value = unquoted
+		}
+	}
+	m.root = newRoot(m.fieldPath, value)
+	m.refreshRows()
+}
+
+func (m *TreeModel) refreshRows() {
+	rows := m.root.rows(m.expanded)
+	items := make([]list.Item, len(rows))
+	for i, row := range rows {
+		items[i] = treeItem{node: row, expanded: m.expanded[row.path]}
+	}
+	m.list.SetItems(items)
+}
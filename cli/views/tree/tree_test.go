@@ -0,0 +1,107 @@
+package tree
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/thefishhat/tamago/server"
+)
+
+func TestRows(t *testing.T) {
+	t.Parallel()
+
+	root := newRoot("Object", map[string]interface{}{
+		"Position": map[string]interface{}{"X": 1.0, "Y": 2.0},
+		"Tags":     []interface{}{"player"},
+		"Speeds":   map[string]interface{}{"1": 0.5},
+		"Name":     "donburi",
+	})
+
+	format := func(expanded map[string]bool) []string {
+		var lines []string
+		for _, row := range root.rows(expanded) {
+			lines = append(lines, treeItem{node: row, expanded: expanded[row.path]}.Title()+" @ "+row.path)
+		}
+		return lines
+	}
+
+	expected := []string{
+		`  Name: "donburi" @ Object.Name`,
+		`▸ Position: {2} @ Object.Position`,
+		`▸ Speeds: {1} @ Object.Speeds`,
+		`▸ Tags: [1] @ Object.Tags`,
+	}
+	if rows := format(map[string]bool{}); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %q, got %q", expected, rows)
+	}
+
+	expected = []string{
+		`  Name: "donburi" @ Object.Name`,
+		`▸ Position: {2} @ Object.Position`,
+		`▾ Speeds: {1} @ Object.Speeds`,
+		`    1: 0.5 @ Object.Speeds[1]`,
+		`▾ Tags: [1] @ Object.Tags`,
+		`    [0]: "player" @ Object.Tags[0]`,
+	}
+	expanded := map[string]bool{"Object.Speeds": true, "Object.Tags": true}
+	if rows := format(expanded); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %q, got %q", expected, rows)
+	}
+}
+
+func TestRowsPrimitive(t *testing.T) {
+	t.Parallel()
+
+	rows := newRoot("Position.X", 1.5).rows(nil)
+	if len(rows) != 1 || rows[0].name != "value" || rows[0].path != "Position.X" || rows[0].summary() != "1.5" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+}
+
+func TestJump(t *testing.T) {
+	t.Parallel()
+
+	m := &TreeModel{
+		list:     list.New(nil, newItemDelegate(), 0, 0),
+		expanded: make(map[string]bool),
+	}
+	m.setValue(&server.ComponentResponse{
+		Value: map[string]interface{}{
+			"OnGround": map[string]interface{}{server.RefKey: "Space.Objects[1]"},
+			"Platform": map[string]interface{}{server.LinkKey: map[string]interface{}{
+				"entity": "3", "component": "Object", "path": "",
+			}},
+			"Space": map[string]interface{}{
+				"Objects": []interface{}{
+					map[string]interface{}{"X": 1.0},
+					map[string]interface{}{"X": 2.0},
+				},
+			},
+		},
+		Type: server.ComponentTypeObject,
+	})
+
+	if summary := m.root.find("OnGround").summary(); summary != "-> Space.Objects[1]" {
+		t.Errorf("Unexpected summary %s", summary)
+	}
+	platform := m.root.find("Platform")
+	if !platform.isLeaf() || platform.summary() != "-> entity 3 Object" {
+		t.Errorf("Unexpected link %s", platform.summary())
+	}
+
+	if cmd := m.jump("Space.Objects[1]"); cmd != nil {
+		t.Fatal("Expected to jump within the tree")
+	}
+	selected := m.list.SelectedItem().(treeItem)
+	if selected.path != "Space.Objects[1]" {
+		t.Errorf("Expected Space.Objects[1] to be selected, got %s", selected.path)
+	}
+	if !m.expanded["Space"] || !m.expanded["Space.Objects"] {
+		t.Errorf("Expected the ancestors to be expanded, got %v", m.expanded)
+	}
+
+	if cmd := m.jump("Other"); cmd == nil {
+		t.Error("Expected paths outside of the tree to be opened")
+	}
+}
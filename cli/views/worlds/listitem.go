@@ -0,0 +1,15 @@
+package worlds
+
+import (
+	"fmt"
+
+	"github.com/thefishhat/tamago/server"
+)
+
+type worldItem struct {
+	server.WorldSummary
+}
+
+func (i worldItem) Title() string       { return i.Name }
+func (i worldItem) Description() string { return fmt.Sprintf("%d entities", i.EntityCount) }
+func (i worldItem) FilterValue() string { return i.Name }
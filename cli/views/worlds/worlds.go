@@ -0,0 +1,92 @@
+package worlds
+
+import (
+	"log"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/thefishhat/tamago/cli/hotswapmodel"
+	"github.com/thefishhat/tamago/server"
+)
+
+var (
+	docStyle    = lipgloss.NewStyle().Margin(1, 2)
+	errMsgStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+type Client interface {
+	ListWorlds() (*server.ListWorldsResponse, error)
+}
+
+// WorldsModel lets the user pick the world to browse, when the server serves several of them.
+type WorldsModel struct {
+	list   list.Model
+	client Client
+	// open returns the view of the entities of the named world.
+	open func(world string) hotswapmodel.ModelSwapper
+}
+
+func NewWorldsModel(client Client, worlds []server.WorldSummary, open func(world string) hotswapmodel.ModelSwapper) *WorldsModel {
+	list := list.New(formatWorldsAsItems(worlds), newItemDelegate(), 0, 0)
+	list.Title = "Worlds"
+
+	return &WorldsModel{
+		list:   list,
+		client: client,
+		open:   open,
+	}
+}
+
+func (m *WorldsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *WorldsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "r":
+			response, err := m.client.ListWorlds()
+			if err != nil {
+				log.Println("fetching worlds:", err)
+				return m, m.list.NewStatusMessage(errMsgStyle.Render(err.Error()))
+			}
+			m.list.SetItems(formatWorldsAsItems(response.Worlds))
+			return m, nil
+		case "enter":
+			selected, ok := m.list.SelectedItem().(worldItem)
+			if !ok {
+				break
+			}
+			return m, func() tea.Msg {
+				return m.open(selected.Name)
+			}
+		}
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v-1)
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m *WorldsModel) View() string {
+	return docStyle.Render(m.list.View())
+}
+
+func formatWorldsAsItems(worlds []server.WorldSummary) []list.Item {
+	items := make([]list.Item, len(worlds))
+	for i, world := range worlds {
+		items[i] = worldItem{world}
+	}
+	return items
+}
@@ -1,11 +1,17 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/thefishhat/tamago/server"
 )
@@ -13,23 +19,137 @@ import (
 // Client is an HTTP client for the [server.Server].
 type Client struct {
 	Addr string
+	// World is the name of the world the client accesses, see [server.Server.AddWorld].
+	// If empty, the client accesses the world the server was started with.
+	World string
+	// Token is sent in the Authorization header of the requests, if the server requires one, see [server.Config.Token].
+	Token string
+
+	httpClient *http.Client
 }
 
 // NewClient creates a new client for the given address.
+// Addresses such as "unix:///path/to.sock" connect to the server through a unix socket.
 func NewClient(addr string) *Client {
+	httpClient := http.DefaultClient
+	if path, ok := server.SocketPath(addr); ok {
+		dialer := &net.Dialer{}
+		httpClient = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", path)
+				},
+			},
+		}
+	}
 	return &Client{
-		Addr: addr,
+		Addr:       addr,
+		httpClient: httpClient,
+	}
+}
+
+// InWorld returns a copy of the client accessing the named world.
+func (c *Client) InWorld(name string) *Client {
+	world := *c
+	world.World = name
+	return &world
+}
+
+// client returns the HTTP client sending the requests, which dials the unix socket of the address, if any,
+// and authenticates the requests with the token.
+func (c *Client) client() *http.Client {
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if c.Token == "" {
+		return httpClient
+	}
+
+	withToken := *httpClient
+	withToken.Transport = tokenTransport{token: c.Token, next: httpClient.Transport}
+	return &withToken
+}
+
+// tokenTransport sets the Authorization header of the requests.
+type tokenTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	// A RoundTripper must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return next.RoundTrip(req)
+}
+
+// baseURL returns the URL of the server. Requests through a unix socket are sent to the "unix" host.
+func (c *Client) baseURL() string {
+	if _, ok := server.SocketPath(c.Addr); ok {
+		return "http://unix"
 	}
+	return "http://" + c.Addr
+}
+
+// worldURL returns the URL of the routes of the client's world.
+func (c *Client) worldURL() string {
+	if c.World == "" {
+		return c.baseURL()
+	}
+	return fmt.Sprintf("%s/worlds/%s", c.baseURL(), url.PathEscape(c.World))
+}
+
+// ListWorlds fetches the worlds served by the server.
+func (c *Client) ListWorlds() (*server.ListWorldsResponse, error) {
+	resp, err := c.client().Get(fmt.Sprintf("%s/worlds", c.baseURL()))
+	if err != nil {
+		return nil, fmt.Errorf("fetching worlds: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.ListWorldsResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
 }
 
 // GetEntity fetches the entity with the given ID from the server.
 func (c *Client) GetEntity(entityID string) (*server.GetEntityResponse, error) {
-	resp, err := http.Get(fmt.Sprintf("http://%s/entities/%s", c.Addr, entityID))
+	resp, err := c.client().Get(fmt.Sprintf("%s/entities/%s", c.worldURL(), entityID))
 	if err != nil {
 		return nil, fmt.Errorf("fetching entity: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.GetEntityResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// GetEntityWithDepth is like [Client.GetEntity], but fetches the component values up to the given depth.
+func (c *Client) GetEntityWithDepth(entityID string, depth int) (*server.GetEntityResponse, error) {
+	resp, err := c.client().Get(fmt.Sprintf("%s/entities/%s?depth=%d", c.worldURL(), entityID, depth))
+	if err != nil {
+		return nil, fmt.Errorf("fetching entity: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
 	var response server.GetEntityResponse
 	json.NewDecoder(resp.Body).Decode(&response)
 	return &response, nil
@@ -37,17 +157,110 @@ func (c *Client) GetEntity(entityID string) (*server.GetEntityResponse, error) {
 
 // GetEntities fetches all entities from the server.
 func (c *Client) GetEntities() (*server.ListEntitiesResponse, error) {
-	resp, err := http.Get(fmt.Sprintf("http://%s/entities", c.Addr))
+	return c.FilterEntities(server.EntityFilter{})
+}
+
+// FilterEntities fetches the entities matching the filter from the server.
+// Example:
+//
+//	client.FilterEntities(server.EntityFilter{Tags: []string{"Player"}}) // fetches all players.
+func (c *Client) FilterEntities(filter server.EntityFilter) (*server.ListEntitiesResponse, error) {
+	entitiesUrl := fmt.Sprintf("%s/entities", c.worldURL())
+	if !filter.IsEmpty() {
+		entitiesUrl += "?" + filter.Query().Encode()
+	}
+	resp, err := c.client().Get(entitiesUrl)
 	if err != nil {
 		return nil, fmt.Errorf("fetching entities: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.ListEntitiesResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// Query fetches the entities matching the filter expression from the server.
+// Example:
+//
+//	filter, _ := server.ParseQueryFilter("and(Object, Tween, not(Player))")
+//	client.Query(filter) // fetches all entities with Object and Tween but not Player.
+func (c *Client) Query(filter server.QueryFilter) (*server.ListEntitiesResponse, error) {
+	b, err := json.Marshal(filter)
+	if err != nil {
+		return nil, fmt.Errorf("encoding filter: %w", err)
+	}
+
+	resp, err := c.client().Get(fmt.Sprintf("%s/query?filter=%s", c.worldURL(), url.QueryEscape(string(b))))
+	if err != nil {
+		return nil, fmt.Errorf("querying entities: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
 	var response server.ListEntitiesResponse
 	json.NewDecoder(resp.Body).Decode(&response)
 	return &response, nil
 }
 
+// CreateEntity creates an entity with the components with the given names.
+// The values optionally hold initial field values, keyed by component name and field path.
+// Example:
+//
+//	client.CreateEntity([]string{"Object", "Player"}, map[string]map[string]interface{}{
+//		"Object": {"X": 10, "Y": 20},
+//	})
+func (c *Client) CreateEntity(components []string, values map[string]map[string]interface{}) (*server.GetEntityResponse, error) {
+	body, err := json.Marshal(server.CreateEntityRequest{
+		Components: components,
+		Values:     values,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	resp, err := c.client().Post(fmt.Sprintf("%s/entities", c.worldURL()), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating entity: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, responseError(resp)
+	}
+
+	var response server.GetEntityResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// DeleteEntity removes the entity with the given ID from the world.
+func (c *Client) DeleteEntity(entityID string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/entities/%s", c.worldURL(), entityID), nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("deleting entity: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return responseError(resp)
+	}
+
+	return nil
+}
+
 // GetComponent fetches the component with the given name from the entity with the given ID.
 // If the fieldPath is not empty, it will fetch the field at the given path.
 // The fieldPath is a dot-separated path to the field in the component.
@@ -56,47 +269,384 @@ func (c *Client) GetEntities() (*server.ListEntitiesResponse, error) {
 //	"position.x" // will fetch the x field from the position component.
 //	"inventory.items[0].name" // will fetch the name field from the first item in the inventory component.
 func (c *Client) GetComponent(entityID string, componentName string, fieldPath string) (*server.ComponentResponse, error) {
-	componentUrl := fmt.Sprintf("http://%s/entities/%s/components/%s", c.Addr, entityID, componentName)
+	componentUrl := fmt.Sprintf("%s/entities/%s/components/%s", c.worldURL(), entityID, componentName)
 	if fieldPath != "" {
-		componentUrl += "?field=" + fieldPath
+		componentUrl += "?field=" + url.QueryEscape(fieldPath)
 	}
-	resp, err := http.Get(componentUrl)
+	resp, err := c.client().Get(componentUrl)
 	if err != nil {
 		return nil, fmt.Errorf("fetching component: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
 	var response server.ComponentResponse
 	json.NewDecoder(resp.Body).Decode(&response)
 	return &response, nil
 }
 
+// GetComponentWithDepth is like [Client.GetComponent], but fetches nested values up to the given depth.
+// A depth of [server.FullDepth] fetches the whole value at once.
+func (c *Client) GetComponentWithDepth(entityID string, componentName string, fieldPath string, depth int) (*server.ComponentResponse, error) {
+	query := url.Values{}
+	query.Set("field", fieldPath)
+	query.Set("depth", strconv.Itoa(depth))
+	resp, err := c.client().Get(fmt.Sprintf("%s/entities/%s/components/%s?%s", c.worldURL(), entityID, componentName, query.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("fetching component: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.ComponentResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// AddComponent adds the component with the given name to the entity with the given ID.
+// The values optionally hold initial field values, keyed by field path.
+func (c *Client) AddComponent(entityID string, componentName string, values map[string]interface{}) (*server.GetEntityResponse, error) {
+	body, err := json.Marshal(server.AddComponentRequest{
+		Values: values,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	resp, err := c.client().Post(fmt.Sprintf("%s/entities/%s/components/%s", c.worldURL(), entityID, componentName), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("adding component: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, responseError(resp)
+	}
+
+	var response server.GetEntityResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// RemoveComponent removes the component with the given name from the entity with the given ID.
+func (c *Client) RemoveComponent(entityID string, componentName string) (*server.GetEntityResponse, error) {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/entities/%s/components/%s", c.worldURL(), entityID, componentName), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("removing component: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.GetEntityResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
 // SetComponent sets the value of the field at the given path in the component with the given name.
 // The value can be any JSON-serializable value.
 // Example:
 //
 //	client.SetComponent("1", "position", "x", 10) // sets the x field in the position component to 10.
 func (c *Client) SetComponent(entityID string, componentName string, fieldPath string, value interface{}) error {
-	url := fmt.Sprintf("http://%s/entities/%s/components/%s?field=%s", c.Addr, entityID, componentName, fieldPath)
-	req, err := http.NewRequest("PUT", url, nil)
+	body, err := json.Marshal(server.SetComponentRequest{Value: value})
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+
+	componentUrl := fmt.Sprintf("%s/entities/%s/components/%s?field=%s", c.worldURL(), entityID, componentName, url.QueryEscape(fieldPath))
+	req, err := http.NewRequest(http.MethodPut, componentUrl, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	buf := []byte(fmt.Sprintf(`{"value": %s}`, value))
-	req.Body = io.NopCloser(bytes.NewReader(buf))
-
-	client := &http.Client{}
-	res, err := client.Do(req)
+	resp, err := c.client().Do(req)
 	if err != nil {
-		return fmt.Errorf("sending request: %w", err)
+		return fmt.Errorf("setting component: %w", err)
 	}
-	defer res.Body.Close()
+	defer resp.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed request: %d", res.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
 	}
 
 	return nil
 }
+
+// ModifyCollection applies an operation to the slice or map field at the given path,
+// and returns the field's value after the operation.
+// Example:
+//
+//	client.ModifyCollection("1", "Inventory", "Items", server.ModifyCollectionRequest{
+//		Op:    server.OpAppend,
+//		Value: "sword",
+//	}) // appends "sword" to the items in the inventory component.
+func (c *Client) ModifyCollection(entityID string, componentName string, fieldPath string, req server.ModifyCollectionRequest) (*server.ComponentResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	componentUrl := fmt.Sprintf("%s/entities/%s/components/%s?field=%s", c.worldURL(), entityID, componentName, url.QueryEscape(fieldPath))
+	httpReq, err := http.NewRequest(http.MethodPatch, componentUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("modifying collection: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.ComponentResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// GetHistory fetches the edits made through the server, from oldest to newest.
+func (c *Client) GetHistory() (*server.GetHistoryResponse, error) {
+	resp, err := c.client().Get(fmt.Sprintf("%s/history", c.worldURL()))
+	if err != nil {
+		return nil, fmt.Errorf("fetching history: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.GetHistoryResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// Undo reverts the last edit made through the server.
+func (c *Client) Undo() (*server.HistoryEntry, error) {
+	return c.applyHistory("undo")
+}
+
+// Redo reapplies the last edit that has been undone.
+func (c *Client) Redo() (*server.HistoryEntry, error) {
+	return c.applyHistory("redo")
+}
+
+func (c *Client) applyHistory(action string) (*server.HistoryEntry, error) {
+	resp, err := c.client().Post(fmt.Sprintf("%s/history/%s", c.worldURL(), action), "application/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("sending %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.HistoryEntry
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// GetLoop fetches the status of the game loop.
+func (c *Client) GetLoop() (*server.LoopStatus, error) {
+	resp, err := c.client().Get(fmt.Sprintf("%s/loop", c.baseURL()))
+	if err != nil {
+		return nil, fmt.Errorf("fetching loop status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.LoopStatus
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// PauseLoop pauses the simulation of the game loop.
+func (c *Client) PauseLoop() (*server.LoopStatus, error) {
+	return c.controlLoop("pause")
+}
+
+// ResumeLoop resumes the simulation of the game loop.
+func (c *Client) ResumeLoop() (*server.LoopStatus, error) {
+	return c.controlLoop("resume")
+}
+
+// StepLoop simulates the given number of frames, then pauses the game loop.
+func (c *Client) StepLoop(frames int) (*server.LoopStatus, error) {
+	return c.controlLoop(fmt.Sprintf("step?frames=%d", frames))
+}
+
+func (c *Client) controlLoop(action string) (*server.LoopStatus, error) {
+	resp, err := c.client().Post(fmt.Sprintf("%s/loop/%s", c.baseURL(), action), "application/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("controlling loop: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.LoopStatus
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// GetSnapshot fetches a snapshot of all entities in the world from the server.
+func (c *Client) GetSnapshot() (*server.Snapshot, error) {
+	resp, err := c.client().Get(fmt.Sprintf("%s/snapshot", c.worldURL()))
+	if err != nil {
+		return nil, fmt.Errorf("fetching snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+	return &response, nil
+}
+
+// RestoreSnapshot replaces all entities in the world with the ones in the snapshot.
+func (c *Client) RestoreSnapshot(snapshot *server.Snapshot) (*server.RestoreSnapshotResponse, error) {
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	resp, err := c.client().Post(fmt.Sprintf("%s/snapshot", c.worldURL()), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("restoring snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.RestoreSnapshotResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// DiffSnapshot compares the snapshot to another one on the server.
+// If to is nil, the snapshot is compared to the live world.
+func (c *Client) DiffSnapshot(from *server.Snapshot, to *server.Snapshot) (*server.SnapshotDiff, error) {
+	body, err := json.Marshal(server.DiffSnapshotRequest{
+		From: *from,
+		To:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	resp, err := c.client().Post(fmt.Sprintf("%s/snapshot/diff", c.worldURL()), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("diffing snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	var response server.SnapshotDiff
+	json.NewDecoder(resp.Body).Decode(&response)
+	return &response, nil
+}
+
+// Watch subscribes to entity creation and removal in the world.
+// If entityID is not empty, it also subscribes to changes of the entity's components,
+// optionally narrowed down to the component with the given name and the field at fieldPath.
+// The current values of the watched fields are sent first.
+//
+// The returned channel is closed when ctx is done or the server ends the stream.
+func (c *Client) Watch(ctx context.Context, entityID string, componentName string, fieldPath string) (<-chan server.Event, error) {
+	query := url.Values{}
+	if entityID != "" {
+		query.Set("entity", entityID)
+	}
+	if componentName != "" {
+		query.Set("component", componentName)
+	}
+	if fieldPath != "" {
+		query.Set("field", fieldPath)
+	}
+	eventsUrl := fmt.Sprintf("%s/events?%s", c.worldURL(), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, eventsUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("subscribing to events: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed request: %d", resp.StatusCode)
+	}
+
+	events := make(chan server.Event)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var event server.Event
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// responseError formats an unexpected response, including the error message sent by the server.
+func responseError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	if len(msg) == 0 {
+		return fmt.Errorf("failed request: %d", resp.StatusCode)
+	}
+	return fmt.Errorf("failed request: %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
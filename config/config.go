@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -10,7 +11,31 @@ import (
 // Config holds the configuration for the editor and all its components.
 type Config struct {
 	// Addr is the address the server will listen on.
+	// Port 0, e.g. ":0", lets the system choose a free port, which clients find in the DiscoveryFile.
+	// Addresses such as "unix:///path/to.sock" use a unix socket instead, and "unix://" a socket
+	// named after the process in the runtime directory.
 	Addr string `envconfig:"SERVER_URL" default:":8080"`
+	// DiscoveryFile is the file the server writes the address it listens on to, if set.
+	// The CLI connects to the address in the file instead of Addr, while the file exists.
+	DiscoveryFile string `envconfig:"DISCOVERY_FILE"`
+
+	// Token is a shared secret that clients must send to access the server. Without a token,
+	// the server only listens on the loopback interface unless Addr has a host, e.g. "0.0.0.0:8080".
+	Token string `envconfig:"TOKEN"`
+
+	// ReadOnly rejects the requests that modify the world, e.g. for builds handed to QA.
+	// The game loop can still be paused, stepped and resumed.
+	ReadOnly bool `envconfig:"READ_ONLY"`
+	// EditAllow lists the component names or field paths, e.g. "Player.Health", that may be edited, if not empty.
+	EditAllow []string `envconfig:"EDIT_ALLOW"`
+	// EditDeny lists the component names or field paths that may not be edited.
+	EditDeny []string `envconfig:"EDIT_DENY"`
+
+	// InspectorMode is either "events", to update the store on entity creation and removal,
+	// or "polling", to periodically introspect the whole world.
+	InspectorMode string `envconfig:"INSPECTOR_MODE" default:"events"`
+	// InspectorInterval is the introspection interval in "polling" mode.
+	InspectorInterval time.Duration `envconfig:"INSPECTOR_INTERVAL" default:"3s"`
 }
 
 // LoadConfig loads the configuration from environment variables or the .env file.
@@ -1,41 +1,167 @@
 package editor
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/thefishhat/tamago/config"
 	"github.com/thefishhat/tamago/inspector"
+	"github.com/thefishhat/tamago/loop"
 	"github.com/thefishhat/tamago/server"
 	"github.com/thefishhat/tamago/store"
 	"github.com/yohamta/donburi/ecs"
 )
 
-// Editor is currently empty, but will be extended in the future.
-type Editor struct{}
+// Editor controls the game loop of the ECS it is attached to.
+type Editor struct {
+	ecs       *ecs.ECS
+	queue     *loop.Queue
+	loop      *loop.Controller
+	inspector *inspector.Inspector
+	server    *server.Server
+	options   options
+
+	mu sync.Mutex
+	// worlds holds the inspectors of the worlds added with [Editor.AddWorld], by name.
+	worlds map[string]*inspector.Inspector
+}
 
 // Attach creates an in-memory store to format and cache the ECS data.
-// It also creates an inspector that periodically updates the store with the latest ECS data.
+// It also creates an inspector that keeps the store up to date with the latest ECS data.
 // Finally, it starts a server that can be accessed using a CLI client.
 //
-// The editor can be configured using env variables. See [config.Config].
-func Attach(ecs *ecs.ECS) (*Editor, error) {
-	cfg := config.LoadConfig()
-
-	editor := &Editor{}
+// Reads and edits made through the server are queued and applied by a system
+// added to the ECS, so they never race with the other systems.
+// The server can only respond while [ecs.ECS.Update] or [Editor.Update] is being called.
+//
+// The editor is configured using env variables, see [config.Config], which options override.
+// Call [Editor.Close] to stop the inspector and the server, e.g. before attaching an editor to the next scene.
+func Attach(ecs *ecs.ECS, opts ...Option) (*Editor, error) {
+	options := newOptions(config.LoadConfig())
+	for _, opt := range opts {
+		opt(&options)
+	}
 
 	store := store.NewStore(ecs)
 
-	_, err := inspector.Start(store)
+	insp, err := inspector.Start(store, inspector.Config{
+		Mode:     options.inspectorMode,
+		Interval: options.inspectorInterval,
+		Logger:   options.logger,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("starting inspector: %w", err)
 	}
 
-	_, err = server.Start(store, server.Config{
-		Addr: cfg.Addr,
+	editor := &Editor{
+		ecs:       ecs,
+		queue:     loop.NewQueue(),
+		loop:      loop.NewController(),
+		inspector: insp,
+		options:   options,
+		worlds:    make(map[string]*inspector.Inspector),
+	}
+
+	editor.server, err = server.Start(store, server.Config{
+		Addr:          options.addr,
+		DiscoveryFile: options.discoveryFile,
+		Token:         options.token,
+		Executor:      editor.queue,
+		Loop:          editor.loop,
+		World:         options.world,
+		Codecs:        options.codecs,
+		ReadOnly:      options.readOnly,
+		EditAllow:     options.editAllow,
+		EditDeny:      options.editDeny,
+		Logger:        options.logger,
+		Handlers:      options.handlers,
 	})
 	if err != nil {
+		insp.Stop()
 		return nil, fmt.Errorf("starting server: %w", err)
 	}
+	ecs.AddSystem(editor.queue.System)
 
 	return editor, nil
 }
+
+// Close stops the inspector and shuts down the server, waiting for the requests in progress until the context is done.
+// Requests waiting for the game loop are cancelled. The system added to the ECS stays, but has nothing left to run.
+func (e *Editor) Close(ctx context.Context) error {
+	e.inspector.Stop()
+	e.mu.Lock()
+	for _, insp := range e.worlds {
+		insp.Stop()
+	}
+	e.mu.Unlock()
+	if err := e.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutting down server: %w", err)
+	}
+	return nil
+}
+
+// Addr returns the address the server listens on.
+func (e *Editor) Addr() string {
+	return e.server.Addr()
+}
+
+// AddWorld makes another ECS available to the clients under the name, e.g. the ECS of a menu
+// or of an overlay, next to the ECS the editor is attached to. It can be picked in the CLI.
+//
+// Like the attached ECS, the requests to the world are applied by a system added to it,
+// so they are only answered while the ECS is being updated. Pausing the game loop only
+// applies to the attached ECS.
+func (e *Editor) AddWorld(name string, ecs *ecs.ECS) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.worlds[name]; ok {
+		return fmt.Errorf("world %q already exists", name)
+	}
+
+	store := store.NewStore(ecs)
+	insp, err := inspector.Start(store, inspector.Config{
+		Mode:     e.options.inspectorMode,
+		Interval: e.options.inspectorInterval,
+		Logger:   e.options.logger,
+	})
+	if err != nil {
+		return fmt.Errorf("starting inspector: %w", err)
+	}
+
+	queue := loop.NewQueue()
+	if err := e.server.AddWorld(name, store, queue); err != nil {
+		insp.Stop()
+		return err
+	}
+	ecs.AddSystem(queue.System)
+	e.worlds[name] = insp
+	return nil
+}
+
+// RemoveWorld stops serving a world added with [Editor.AddWorld], e.g. when its scene is torn down.
+func (e *Editor) RemoveWorld(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	insp, ok := e.worlds[name]
+	if !ok {
+		return fmt.Errorf("world %q not found", name)
+	}
+	if err := e.server.RemoveWorld(name); err != nil {
+		return err
+	}
+	insp.Stop()
+	delete(e.worlds, name)
+	return nil
+}
+
+// Update calls [ecs.ECS.Update], unless the game loop has been paused through the editor.
+// Call it instead of [ecs.ECS.Update] to be able to pause and step the simulation,
+// while the game keeps drawing the world.
+//
+// Requests made through the server are also applied while the game loop is paused.
+func (e *Editor) Update() {
+	if !e.loop.Update(e.ecs) {
+		e.queue.Flush()
+	}
+}
@@ -2,6 +2,7 @@ package scenes
 
 import (
 	"image/color"
+	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/solarlune/resolv"
@@ -16,11 +17,12 @@ import (
 )
 
 type PlatformerScene struct {
-	ecs *ecs.ECS
+	ecs    *ecs.ECS
+	editor *editor.Editor
 }
 
 func (ps *PlatformerScene) Update() {
-	ps.ecs.Update()
+	ps.editor.Update()
 }
 
 func (ps *PlatformerScene) Draw(screen *ebiten.Image) {
@@ -32,7 +34,11 @@ func NewPlatformerScene() *PlatformerScene {
 	ps := &PlatformerScene{}
 
 	ecs := ecs.NewECS(donburi.NewWorld())
-	editor.Attach(ecs)
+	var err error
+	ps.editor, err = editor.Attach(ecs)
+	if err != nil {
+		log.Fatal("attaching editor:", err)
+	}
 
 	ecs.AddSystem(systems.UpdateFloatingPlatform)
 	ecs.AddSystem(systems.UpdatePlayer)
@@ -3,6 +3,7 @@ package inspector
 import (
 	"fmt"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-co-op/gocron/v2"
@@ -13,41 +14,88 @@ type Store interface {
 	GetWorld() donburi.World
 	GetEntries() map[uint32]*donburi.Entry
 	SetEntries(map[uint32]*donburi.Entry)
+	AddEntry(entry *donburi.Entry)
+	RemoveEntry(id uint32)
+}
+
+// Mode selects how the inspector keeps the store in sync with the world.
+type Mode string
+
+const (
+	// ModeEvents updates the store from donburi's entity create/remove callbacks,
+	// so the store is always consistent with the world.
+	ModeEvents Mode = "events"
+	// ModePolling periodically re-introspects the whole world.
+	ModePolling Mode = "polling"
+)
+
+// DefaultInterval is the polling interval used when [Config.Interval] is not set.
+const DefaultInterval = 3 * time.Second
+
+type Config struct {
+	Mode Mode
+	// Interval is the introspection interval in [ModePolling].
+	Interval time.Duration
+	// Logger receives the inspector logs, prefixed with "[inspector]". If nil, the standard logger is used.
+	Logger *log.Logger
 }
 
 type Inspector struct {
 	store     Store
 	scheduler gocron.Scheduler
+	stopped   atomic.Bool
 }
 
-// The inspector will periodically introspect the ECS and update the store with the latest entries.
+// Start introspects the ECS and keeps the store up to date with the latest entries.
 //
-// The interval of introspection is 3 seconds.
-func Start(store Store) (*Inspector, error) {
-	log := log.New(log.Writer(), "[inspector] ", log.LstdFlags)
-
-	s, err := gocron.NewScheduler()
-	if err != nil {
-		return nil, fmt.Errorf("creating scheduler: %w", err)
+// In [ModeEvents] (the default) the store is updated whenever an entity is created or removed.
+// In [ModePolling] the ECS is introspected every [Config.Interval].
+func Start(store Store, cfg Config) (*Inspector, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.Default()
 	}
+	log := log.New(logger.Writer(), "[inspector] ", logger.Flags())
 
 	inspector := &Inspector{
-		store:     store,
-		scheduler: s,
+		store: store,
 	}
 
-	_, err = s.NewJob(
-		gocron.DurationJob(
-			3*time.Second,
-		),
-		gocron.NewTask(inspector.IntrospectECS),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("creating ECS job: %w", err)
+	switch cfg.Mode {
+	case ModeEvents, "":
+		inspector.IntrospectECS()
+		inspector.subscribe()
+		log.Println("Subscribed to ECS world events")
+
+	case ModePolling:
+		interval := cfg.Interval
+		if interval <= 0 {
+			interval = DefaultInterval
+		}
+
+		s, err := gocron.NewScheduler()
+		if err != nil {
+			return nil, fmt.Errorf("creating scheduler: %w", err)
+		}
+		inspector.scheduler = s
+
+		_, err = s.NewJob(
+			gocron.DurationJob(
+				interval,
+			),
+			gocron.NewTask(inspector.IntrospectECS),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("creating ECS job: %w", err)
+		}
+		log.Println("Created ECS introspection job")
+
+		s.Start()
+
+	default:
+		return nil, fmt.Errorf("unknown inspector mode %q", cfg.Mode)
 	}
-	log.Println("Created ECS introspection job")
 
-	s.Start()
 	log.Println("Inspector started")
 
 	return inspector, nil
@@ -69,7 +117,28 @@ func (i *Inspector) IntrospectECS() {
 	i.store.SetEntries(newEntries)
 }
 
+// subscribe registers world callbacks that mirror entity creation and removal in the store.
+// donburi does not support unregistering callbacks, so they become no-ops once the inspector is stopped.
+func (i *Inspector) subscribe() {
+	world := i.store.GetWorld()
+	world.OnCreate(func(world donburi.World, entity donburi.Entity) {
+		if i.stopped.Load() {
+			return
+		}
+		i.store.AddEntry(world.Entry(entity))
+	})
+	world.OnRemove(func(_ donburi.World, entity donburi.Entity) {
+		if i.stopped.Load() {
+			return
+		}
+		i.store.RemoveEntry(uint32(entity.Id()))
+	})
+}
+
 // Stop stops the inspector.
 func (i *Inspector) Stop() {
-	i.scheduler.Shutdown()
+	i.stopped.Store(true)
+	if i.scheduler != nil {
+		i.scheduler.Shutdown()
+	}
 }
@@ -0,0 +1,73 @@
+package inspector_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thefishhat/tamago/inspector"
+	"github.com/thefishhat/tamago/store"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+type MockComponent struct{}
+
+func TestInspector_Events(t *testing.T) {
+	world := donburi.NewWorld()
+	mockComponent := donburi.NewComponentType[MockComponent]()
+	existing := world.Create(mockComponent)
+	st := store.NewStore(ecs.NewECS(world))
+
+	insp, err := inspector.Start(st, inspector.Config{Mode: inspector.ModeEvents})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	assert.NotNil(t, st.GetEntry(uint32(existing.Id())), "existing entities should be introspected on start")
+
+	created := world.Create(mockComponent)
+	assert.NotNil(t, st.GetEntry(uint32(created.Id())), "created entities should be added immediately")
+
+	world.Remove(existing)
+	assert.Nil(t, st.GetEntry(uint32(existing.Id())), "removed entities should be removed immediately")
+	assert.Len(t, st.GetEntries(), 1)
+}
+
+func TestInspector_EventsStopped(t *testing.T) {
+	world := donburi.NewWorld()
+	mockComponent := donburi.NewComponentType[MockComponent]()
+	st := store.NewStore(ecs.NewECS(world))
+
+	insp, err := inspector.Start(st, inspector.Config{Mode: inspector.ModeEvents})
+	require.NoError(t, err)
+	insp.Stop()
+
+	world.Create(mockComponent)
+	assert.Empty(t, st.GetEntries())
+}
+
+func TestInspector_Polling(t *testing.T) {
+	world := donburi.NewWorld()
+	mockComponent := donburi.NewComponentType[MockComponent]()
+	st := store.NewStore(ecs.NewECS(world))
+	entity := world.Create(mockComponent)
+
+	insp, err := inspector.Start(st, inspector.Config{
+		Mode:     inspector.ModePolling,
+		Interval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	assert.Eventually(t, func() bool {
+		return st.GetEntry(uint32(entity.Id())) != nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestInspector_UnknownMode(t *testing.T) {
+	st := store.NewStore(ecs.NewECS(donburi.NewWorld()))
+
+	_, err := inspector.Start(st, inspector.Config{Mode: "bogus"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package loop
+
+import (
+	"sync"
+
+	"github.com/yohamta/donburi/ecs"
+)
+
+// Controller pauses, resumes and single-steps the simulation of an ECS.
+// It is safe to use from other goroutines than the game loop.
+type Controller struct {
+	mu     sync.Mutex
+	paused bool
+	// steps is the number of frames left to simulate while paused.
+	steps int
+	frame uint64
+}
+
+// NewController creates a running controller.
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// Pause stops the simulation after the current frame.
+func (c *Controller) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = true
+	c.steps = 0
+}
+
+// Resume continues the simulation.
+func (c *Controller) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = false
+	c.steps = 0
+}
+
+// Step pauses the simulation once the given number of frames has been simulated.
+// Steps add up if the previous ones have not been simulated yet.
+func (c *Controller) Step(frames int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		c.paused = true
+		c.steps = 0
+	}
+	c.steps += frames
+}
+
+// Paused returns true if the simulation is paused, even if steps are pending.
+func (c *Controller) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Frame returns the number of frames simulated through [Controller.Update].
+func (c *Controller) Frame() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.frame
+}
+
+// Update simulates a frame by calling [ecs.ECS.Update], unless the simulation is paused.
+// It returns false if the frame was skipped.
+//
+// While paused, only the ECS time is updated, so the first frame after
+// resuming or stepping does not get a huge delta time.
+func (c *Controller) Update(e *ecs.ECS) bool {
+	if !c.advance() {
+		e.Time.Update()
+		return false
+	}
+	e.Update()
+	return true
+}
+
+// advance reports whether the next frame should be simulated and counts it.
+func (c *Controller) advance() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		if c.steps == 0 {
+			return false
+		}
+		c.steps--
+	}
+	c.frame++
+	return true
+}
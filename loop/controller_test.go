@@ -0,0 +1,58 @@
+package loop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+)
+
+func newCountingECS() (*ecs.ECS, *int) {
+	e := ecs.NewECS(donburi.NewWorld())
+	updates := 0
+	e.AddSystem(func(_ *ecs.ECS) {
+		updates++
+	})
+	return e, &updates
+}
+
+func TestController_PauseResume(t *testing.T) {
+	e, updates := newCountingECS()
+	c := NewController()
+
+	assert.True(t, c.Update(e))
+	c.Pause()
+	assert.False(t, c.Update(e))
+	assert.True(t, c.Paused())
+	c.Resume()
+	assert.True(t, c.Update(e))
+
+	assert.Equal(t, 2, *updates)
+	assert.Equal(t, uint64(2), c.Frame())
+}
+
+func TestController_Step(t *testing.T) {
+	e, updates := newCountingECS()
+	c := NewController()
+
+	c.Step(2)
+	assert.True(t, c.Paused(), "stepping should pause the loop")
+	for i := 0; i < 5; i++ {
+		c.Update(e)
+	}
+
+	assert.Equal(t, 2, *updates)
+	assert.Equal(t, uint64(2), c.Frame())
+}
+
+func TestController_PauseDiscardsSteps(t *testing.T) {
+	e, updates := newCountingECS()
+	c := NewController()
+
+	c.Step(3)
+	c.Pause()
+	c.Update(e)
+
+	assert.Equal(t, 0, *updates)
+}
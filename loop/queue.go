@@ -0,0 +1,95 @@
+package loop
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yohamta/donburi/ecs"
+)
+
+type job struct {
+	fn    func()
+	done  chan struct{}
+	panic any
+}
+
+// Queue collects work submitted from other goroutines (e.g. HTTP handlers)
+// and runs it on the game loop, where it cannot race with donburi systems.
+type Queue struct {
+	mu      sync.Mutex
+	pending []*job
+}
+
+// NewQueue creates an empty queue.
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+// Do enqueues fn and blocks until it has been run by [Queue.Flush].
+//
+// If ctx is done before fn was picked up, fn is dropped and the context error is returned.
+// Once fn has been picked up, Do always waits for it to finish.
+// A panic in fn does not stop the game loop; it is re-raised on the goroutine calling Do.
+func (q *Queue) Do(ctx context.Context, fn func()) error {
+	j := &job{
+		fn:   fn,
+		done: make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	q.pending = append(q.pending, j)
+	q.mu.Unlock()
+
+	select {
+	case <-j.done:
+		return j.result()
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	for i, pending := range q.pending {
+		if pending == j {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			q.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+	q.mu.Unlock()
+
+	// The job is already being run by Flush.
+	<-j.done
+	return j.result()
+}
+
+// Flush runs all pending work in the order it was submitted.
+// It must be called from the goroutine running the game loop.
+func (q *Queue) Flush() {
+	q.mu.Lock()
+	jobs := q.pending
+	q.pending = nil
+	q.mu.Unlock()
+
+	for _, j := range jobs {
+		run(j)
+	}
+}
+
+// System is a donburi system flushing the queue once per frame.
+func (q *Queue) System(_ *ecs.ECS) {
+	q.Flush()
+}
+
+func run(j *job) {
+	defer close(j.done)
+	defer func() {
+		j.panic = recover()
+	}()
+	j.fn()
+}
+
+func (j *job) result() error {
+	if j.panic != nil {
+		panic(j.panic)
+	}
+	return nil
+}
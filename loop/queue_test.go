@@ -0,0 +1,85 @@
+package loop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue_DoWaitsForFlush(t *testing.T) {
+	q := NewQueue()
+	ran := make(chan struct{})
+
+	go func() {
+		err := q.Do(context.Background(), func() {})
+		assert.NoError(t, err)
+		close(ran)
+	}()
+
+	select {
+	case <-ran:
+		t.Fatal("Do returned before the queue was flushed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Eventually(t, func() bool {
+		q.Flush()
+		select {
+		case <-ran:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestQueue_FlushRunsInOrder(t *testing.T) {
+	q := NewQueue()
+	var order []int
+
+	for i := 0; i < 3; i++ {
+		q.pending = append(q.pending, &job{
+			fn:   func() { order = append(order, i) },
+			done: make(chan struct{}),
+		})
+	}
+	q.Flush()
+
+	assert.Equal(t, []int{0, 1, 2}, order)
+	assert.Empty(t, q.pending)
+}
+
+func TestQueue_DoCancelledBeforeFlush(t *testing.T) {
+	q := NewQueue()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	ran := false
+	err := q.Do(ctx, func() { ran = true })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	q.Flush()
+	assert.False(t, ran, "cancelled work should not run")
+}
+
+func TestQueue_DoRepanics(t *testing.T) {
+	q := NewQueue()
+	done := make(chan interface{})
+
+	go func() {
+		defer func() {
+			done <- recover()
+		}()
+		_ = q.Do(context.Background(), func() { panic("boom") })
+	}()
+
+	assert.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return len(q.pending) == 1
+	}, time.Second, time.Millisecond)
+	assert.NotPanics(t, q.Flush)
+	assert.Equal(t, "boom", <-done)
+}
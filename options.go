@@ -0,0 +1,140 @@
+package editor
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/thefishhat/tamago/config"
+	"github.com/thefishhat/tamago/inspector"
+	"github.com/thefishhat/tamago/server"
+)
+
+// Option configures the editor, see [Attach].
+type Option func(*options)
+
+type options struct {
+	addr              string
+	discoveryFile     string
+	token             string
+	world             string
+	inspectorMode     inspector.Mode
+	inspectorInterval time.Duration
+	readOnly          bool
+	editAllow         []string
+	editDeny          []string
+	logger            *log.Logger
+	handlers          map[string]http.Handler
+	codecs            *server.Codecs
+}
+
+func newOptions(cfg config.Config) options {
+	return options{
+		addr:              cfg.Addr,
+		discoveryFile:     cfg.DiscoveryFile,
+		token:             cfg.Token,
+		inspectorMode:     inspector.Mode(cfg.InspectorMode),
+		inspectorInterval: cfg.InspectorInterval,
+		readOnly:          cfg.ReadOnly,
+		editAllow:         cfg.EditAllow,
+		editDeny:          cfg.EditDeny,
+		handlers:          make(map[string]http.Handler),
+		codecs:            server.NewCodecs(),
+	}
+}
+
+// WithAddr sets the address the server listens on, e.g. ":8081".
+func WithAddr(addr string) Option {
+	return func(o *options) {
+		o.addr = addr
+	}
+}
+
+// WithDiscoveryFile writes the address the server listens on to the file, where the CLI finds it,
+// e.g. when the address has port 0 to let the system choose a free port.
+func WithDiscoveryFile(path string) Option {
+	return func(o *options) {
+		o.discoveryFile = path
+	}
+}
+
+// WithToken requires clients to send the token, e.g. to expose the server to the local network.
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithWorldName names the world of the attached ECS, "default" if not set. The name is shown
+// in the CLI when other worlds are added, see [Editor.AddWorld].
+func WithWorldName(name string) Option {
+	return func(o *options) {
+		o.world = name
+	}
+}
+
+// WithInspectorInterval makes the inspector introspect the whole world at the interval,
+// instead of following entity creation and removal, see [inspector.ModePolling].
+func WithInspectorInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.inspectorMode = inspector.ModePolling
+		o.inspectorInterval = interval
+	}
+}
+
+// WithReadOnly prevents clients from modifying the world. They can still inspect it and control the game loop:
+// pausing, stepping and resuming it are allowed.
+func WithReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithEditAllow only lets clients edit the components and fields matched by the patterns, which are
+// component names, e.g. "Player", or field paths prefixed by the component name, e.g. "Player.Health".
+// The fields they hold can be edited too.
+func WithEditAllow(patterns ...string) Option {
+	return func(o *options) {
+		o.editAllow = append(o.editAllow, patterns...)
+	}
+}
+
+// WithEditDeny prevents clients from editing the components and fields matched by the patterns,
+// like [WithEditAllow], even if they are allowed. The CLI shows them as locked.
+func WithEditDeny(patterns ...string) Option {
+	return func(o *options) {
+		o.editDeny = append(o.editDeny, patterns...)
+	}
+}
+
+// WithLogger sends the logs of the inspector and the server to the logger.
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithHandler serves the handler next to the editor routes, e.g. to expose game-specific debug endpoints.
+// The pattern follows the syntax of [http.ServeMux], and must not conflict with the editor routes.
+func WithHandler(pattern string, handler http.Handler) Option {
+	return func(o *options) {
+		o.handlers[pattern] = handler
+	}
+}
+
+// WithEncoder shows the values of type T as the value returned by encode, e.g. a string or a map,
+// instead of browsing them field by field. The encoded value must be JSON-serializable.
+// It replaces the built-in encoder of T, if any, see [server.NewCodecs].
+func WithEncoder[T any](encode func(T) any) Option {
+	return func(o *options) {
+		server.RegisterEncoder(o.codecs, encode)
+	}
+}
+
+// WithDecoder sets the values of type T from the values sent by the client, which are decoded from JSON,
+// e.g. the string typed in the CLI. It replaces the built-in decoder of T, if any, see [server.NewCodecs].
+func WithDecoder[T any](decode func(any) (T, error)) Option {
+	return func(o *options) {
+		server.RegisterDecoder(o.codecs, decode)
+	}
+}
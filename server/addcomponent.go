@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type AddComponentRequest struct {
+	// Values optionally holds initial field values, keyed by field path.
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+// req: POST /entities/3/components/Tween
+// body (optional): {"values": {"Speed": 2}}
+// resp: 201, {"entity": {...}}
+func (s *Server) addComponentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("entity_id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid entity ID", http.StatusBadRequest)
+		return
+	}
+
+	componentName := r.PathValue("component_name")
+	if err := s.policy.checkEditable(componentName, nil, ""); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req AddComponentRequest
+	if err := decodeBody(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var response GetEntityResponse
+	err = s.execute(r.Context(), func() error {
+		entry := s.store.GetEntry(uint32(id))
+		if entry == nil {
+			return errorWithStatus(http.StatusNotFound, "Entity not found")
+		}
+
+		componentType, err := newComponentRegistry(s.store.GetWorld()).lookup(componentName)
+		if err != nil {
+			return err
+		}
+		if entry.HasComponent(componentType) {
+			return errorWithStatus(http.StatusConflict, "Entity already has the component")
+		}
+
+		entry.AddComponent(componentType)
+		component, _ := findComponent(entry, componentName)
+		for fieldPath, value := range req.Values {
+			if err := setField(component, fieldPath, value, s.codecs); err != nil {
+				entry.RemoveComponent(componentType)
+				return fmt.Errorf("setting %s: %w", fieldPath, err)
+			}
+		}
+
+		response = GetEntityResponse{Entity: entityFromEntry(entry, s.codecs)}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	dmath "github.com/yohamta/donburi/features/math"
+)
+
+// Codecs holds the encoders and decoders registered for types that are not useful to browse
+// field by field, e.g. third-party types, or types better edited as a single value.
+//
+// Encoders replace the value in responses, and decoders convert the values sent to set fields of the type.
+// They are used for values of the exact type they are registered for, and pointers to them.
+type Codecs struct {
+	encoders map[reflect.Type]func(reflect.Value) interface{}
+	decoders map[reflect.Type]valueDecoder
+}
+
+type valueDecoder struct {
+	decode func(interface{}) (reflect.Value, error)
+	// stringsOnly is set for the built-in decoders, which leave the other values to [decodeValue],
+	// so that objects still set the fields they hold.
+	stringsOnly bool
+}
+
+// NewCodecs returns codecs holding the built-in codecs, which format [image.Rectangle], [image.Point],
+// [color.RGBA], [time.Duration] and donburi's [dmath.Vec2] as strings, such as "(0,0)-(10,10)",
+// "#ff0000ff", "1.5s" and "(1, 2)". Values other than strings are decoded as usual, e.g. objects set the fields they hold.
+func NewCodecs() *Codecs {
+	c := &Codecs{
+		encoders: make(map[reflect.Type]func(reflect.Value) interface{}),
+		decoders: make(map[reflect.Type]valueDecoder),
+	}
+
+	RegisterEncoder(c, func(r image.Rectangle) any { return r.String() })
+	registerStringDecoder(c, decodeRectangle)
+	RegisterEncoder(c, func(p image.Point) any { return p.String() })
+	registerStringDecoder(c, decodePoint)
+	RegisterEncoder(c, func(rgba color.RGBA) any {
+		return fmt.Sprintf("#%02x%02x%02x%02x", rgba.R, rgba.G, rgba.B, rgba.A)
+	})
+	registerStringDecoder(c, decodeRGBA)
+	RegisterEncoder(c, func(d time.Duration) any { return d.String() })
+	registerStringDecoder(c, decodeDuration)
+	RegisterEncoder(c, func(v dmath.Vec2) any { return formatVector(v.X, v.Y) })
+	registerStringDecoder(c, func(str string) (dmath.Vec2, error) {
+		var v dmath.Vec2
+		err := decodeVector(str, &v.X, &v.Y)
+		return v, err
+	})
+	return c
+}
+
+// RegisterEncoder registers the encoder of the values of type T, replacing any previous one.
+// The encoded value must be JSON-serializable.
+func RegisterEncoder[T any](c *Codecs, encode func(T) any) {
+	c.encoders[reflect.TypeFor[T]()] = func(value reflect.Value) interface{} {
+		return encode(value.Interface().(T))
+	}
+}
+
+// RegisterDecoder registers the decoder of the values of type T, replacing any previous one.
+// The decoder is passed the JSON-decoded value, see [SetField].
+func RegisterDecoder[T any](c *Codecs, decode func(any) (T, error)) {
+	c.decoders[reflect.TypeFor[T]()] = valueDecoder{decode: func(value interface{}) (reflect.Value, error) {
+		decoded, err := decode(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&decoded).Elem(), nil
+	}}
+}
+
+func registerStringDecoder[T any](c *Codecs, decode func(string) (T, error)) {
+	c.decoders[reflect.TypeFor[T]()] = valueDecoder{
+		decode: func(value interface{}) (reflect.Value, error) {
+			decoded, err := decode(value.(string))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&decoded).Elem(), nil
+		},
+		stringsOnly: true,
+	}
+}
+
+// encode returns the encoded value, if there is an encoder for its type, or the type it points to.
+// Values read through unexported fields cannot be passed to encoders.
+func (c *Codecs) encode(value reflect.Value) (interface{}, bool) {
+	if c == nil || !value.IsValid() || !value.CanInterface() {
+		return nil, false
+	}
+	encode, ok := c.encoders[value.Type()]
+	if !ok && value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+		encode, ok = c.encoders[value.Type()]
+	}
+	if !ok {
+		return nil, false
+	}
+	return encode(value), true
+}
+
+// decoder returns the decoder of the type for the value, if there is one.
+func (c *Codecs) decoder(typ reflect.Type, value interface{}) (func(interface{}) (reflect.Value, error), bool) {
+	if c == nil {
+		return nil, false
+	}
+	decoder, ok := c.decoders[typ]
+	if !ok {
+		return nil, false
+	}
+	if _, isString := value.(string); decoder.stringsOnly && !isString {
+		return nil, false
+	}
+	return decoder.decode, true
+}
+
+func decodeRectangle(str string) (image.Rectangle, error) {
+	var r image.Rectangle
+	_, err := fmt.Sscanf(str, "(%d,%d)-(%d,%d)", &r.Min.X, &r.Min.Y, &r.Max.X, &r.Max.Y)
+	if err != nil {
+		return r, fmt.Errorf("invalid rectangle %q, expected (x0,y0)-(x1,y1)", str)
+	}
+	return r, nil
+}
+
+func decodePoint(str string) (image.Point, error) {
+	var p image.Point
+	_, err := fmt.Sscanf(str, "(%d,%d)", &p.X, &p.Y)
+	if err != nil {
+		return p, fmt.Errorf("invalid point %q, expected (x,y)", str)
+	}
+	return p, nil
+}
+
+// decodeRGBA decodes a color from "#rrggbb" or "#rrggbbaa".
+func decodeRGBA(str string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(str, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, expected #rrggbb or #rrggbbaa", str)
+	}
+	return color.RGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+// decodeDuration decodes a duration from a string such as "1.5s". Numbers of nanoseconds are decoded as any integer.
+func decodeDuration(str string) (time.Duration, error) {
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return d, fmt.Errorf("invalid duration %q", str)
+	}
+	return d, nil
+}
+
+func formatVector(components ...float64) string {
+	formatted := make([]string, len(components))
+	for i, c := range components {
+		formatted[i] = strconv.FormatFloat(c, 'g', -1, 64)
+	}
+	return "(" + strings.Join(formatted, ", ") + ")"
+}
+
+// decodeVector decodes a vector from a string such as "(1, 2)" into its components.
+func decodeVector(str string, components ...*float64) error {
+	fields := strings.Split(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(str), "("), ")"), ",")
+	if len(fields) != len(components) {
+		return fmt.Errorf("invalid vector %q, expected %d components", str, len(components))
+	}
+	for i, field := range fields {
+		f, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return fmt.Errorf("invalid vector %q: %w", str, err)
+		}
+		*components[i] = f
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dmath "github.com/yohamta/donburi/features/math"
+)
+
+type spriteComponent struct {
+	Bounds   image.Rectangle
+	Tint     color.RGBA
+	Cooldown time.Duration
+	Velocity dmath.Vec2
+	Anchor   *image.Point
+}
+
+func TestCodecs_BuiltIn(t *testing.T) {
+	codecs := NewCodecs()
+	sprite := spriteComponent{
+		Bounds:   image.Rect(0, 0, 10, 20),
+		Tint:     color.RGBA{R: 255, A: 128},
+		Cooldown: 1500 * time.Millisecond,
+		Velocity: dmath.Vec2{X: 1, Y: -2.5},
+		Anchor:   &image.Point{X: 3, Y: 4},
+	}
+	component := reflect.ValueOf(&sprite).Elem()
+
+	assert.Equal(t, map[string]interface{}{
+		"Bounds":   "(0,0)-(10,20)",
+		"Tint":     "#ff000080",
+		"Cooldown": "1.5s",
+		"Velocity": "(1, -2.5)",
+		"Anchor":   "(3,4)",
+	}, encodeField(component, "", DefaultDepth, nil, codecs))
+
+	// Encoded values are not described field by field.
+	response, err := getComponentResponse(component, "Bounds", DefaultDepth, nil, codecs)
+	require.NoError(t, err)
+	assert.Equal(t, ComponentTypePrimitive, response.Type)
+	assert.Equal(t, `"(0,0)-(10,20)"`, response.Value)
+	assert.Nil(t, response.Fields)
+
+	require.NoError(t, setField(component, "Bounds", "(1,2)-(3,4)", codecs))
+	require.NoError(t, setField(component, "Tint", "#00ff00", codecs))
+	require.NoError(t, setField(component, "Cooldown", "250ms", codecs))
+	require.NoError(t, setField(component, "Velocity", "(0.5, 2)", codecs))
+	require.NoError(t, setField(component, "Anchor", "(5,6)", codecs))
+	assert.Equal(t, spriteComponent{
+		Bounds:   image.Rect(1, 2, 3, 4),
+		Tint:     color.RGBA{G: 255, A: 255},
+		Cooldown: 250 * time.Millisecond,
+		Velocity: dmath.Vec2{X: 0.5, Y: 2},
+		Anchor:   &image.Point{X: 5, Y: 6},
+	}, sprite)
+
+	// Objects are still accepted.
+	require.NoError(t, setField(component, "Velocity", map[string]interface{}{"Y": 3.0}, codecs))
+	assert.Equal(t, dmath.Vec2{X: 0.5, Y: 3}, sprite.Velocity)
+
+	err = setField(component, "Tint", "red", codecs)
+	assert.EqualError(t, err, `cannot set field: invalid color "red", expected #rrggbb or #rrggbbaa`)
+	err = setField(component, "Bounds", "(1,2)", codecs)
+	assert.EqualError(t, err, `cannot set field: invalid rectangle "(1,2)", expected (x0,y0)-(x1,y1)`)
+	assert.Equal(t, image.Rect(1, 2, 3, 4), sprite.Bounds)
+}
+
+type celsius float64
+
+func TestCodecs_Register(t *testing.T) {
+	codecs := NewCodecs()
+	RegisterEncoder(codecs, func(c celsius) any { return map[string]interface{}{"degrees": float64(c)} })
+	RegisterDecoder(codecs, func(value any) (celsius, error) {
+		degrees, ok := value.(float64)
+		if !ok || degrees < -273.15 {
+			return 0, errors.New("below absolute zero")
+		}
+		return celsius(degrees), nil
+	})
+	// Registering again replaces the built-in encoder.
+	RegisterEncoder(codecs, func(d time.Duration) any { return d.Seconds() })
+
+	component := reflect.ValueOf(&struct {
+		Readings []celsius
+		Timeout  time.Duration
+	}{
+		Readings: []celsius{20.5},
+		Timeout:  2 * time.Second,
+	}).Elem()
+
+	assert.Equal(t, map[string]interface{}{
+		"Readings": []interface{}{map[string]interface{}{"degrees": 20.5}},
+		"Timeout":  2.0,
+	}, encodeField(component, "", FullDepth, nil, codecs))
+
+	require.NoError(t, setField(component, "Readings[0]", -10.0, codecs))
+	assert.Equal(t, []celsius{-10}, component.Field(0).Interface())
+
+	err := setField(component, "Readings[0]", -300.0, codecs)
+	assert.EqualError(t, err, "cannot set field: below absolute zero")
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// CollectionOp is an operation on a slice or map field, see [ModifyCollection].
+type CollectionOp string
+
+const (
+	// OpAppend appends the value to a slice.
+	OpAppend CollectionOp = "append"
+	// OpInsert inserts the value into a slice before the index.
+	OpInsert CollectionOp = "insert"
+	// OpRemove removes the element at the index from a slice.
+	OpRemove CollectionOp = "remove"
+	// OpReplace replaces the element at the index of a slice or array with the value.
+	OpReplace CollectionOp = "replace"
+	// OpSet sets the key of a map to the value.
+	OpSet CollectionOp = "set"
+	// OpDelete deletes the key from a map.
+	OpDelete CollectionOp = "delete"
+)
+
+type ModifyCollectionRequest struct {
+	Op    CollectionOp `json:"op"`
+	Index *int         `json:"index,omitempty"`
+	// Key is a map key, either as a JSON value of the key type or as a string (e.g. "1" for map[int]T).
+	Key   interface{} `json:"key,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ModifyCollection applies the operation to the slice or map field at the given path.
+//
+// The field is replaced by a modified copy of the slice or map, so references
+// taken before the operation, such as the ones kept in the history, are unaffected.
+func ModifyCollection(component reflect.Value, fieldPath string, req ModifyCollectionRequest) error {
+	return modifyCollection(component, fieldPath, req, nil)
+}
+
+// modifyCollection is [ModifyCollection], decoding the values and keys with the decoders in codecs.
+func modifyCollection(component reflect.Value, fieldPath string, req ModifyCollectionRequest, codecs *Codecs) error {
+	field, err := findField(component, fieldPath)
+	if err != nil {
+		return err
+	}
+	if !field.CanSet() {
+		return errors.New("field is not settable")
+	}
+
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		return modifySlice(field, req, codecs)
+	case reflect.Map:
+		return modifyMap(field, req, codecs)
+	default:
+		return fmt.Errorf("field of type %s is not a slice or map", field.Type())
+	}
+}
+
+func modifySlice(field reflect.Value, req ModifyCollectionRequest, codecs *Codecs) error {
+	length := field.Len()
+	index := func(max int) (int, error) {
+		if req.Index == nil {
+			return 0, fmt.Errorf("%s requires an index", req.Op)
+		}
+		if *req.Index < 0 || *req.Index > max {
+			return 0, fmt.Errorf("index %d out of range [0, %d]", *req.Index, max)
+		}
+		return *req.Index, nil
+	}
+	decodeElem := func() (reflect.Value, error) {
+		elem := reflect.New(field.Type().Elem()).Elem()
+		if err := decodeValue(elem, req.Value, codecs); err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot decode element: %w", err)
+		}
+		return elem, nil
+	}
+
+	if field.Kind() == reflect.Array && req.Op != OpReplace {
+		return fmt.Errorf("cannot %s elements of array %s", req.Op, field.Type())
+	}
+
+	switch req.Op {
+	case OpAppend, OpInsert:
+		i := length
+		if req.Op == OpInsert {
+			var err error
+			if i, err = index(length); err != nil {
+				return err
+			}
+		}
+		elem, err := decodeElem()
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(field.Type(), 0, length+1)
+		slice = reflect.AppendSlice(slice, field.Slice(0, i))
+		slice = reflect.Append(slice, elem)
+		slice = reflect.AppendSlice(slice, field.Slice(i, length))
+		field.Set(slice)
+
+	case OpRemove:
+		i, err := index(length - 1)
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(field.Type(), 0, length-1)
+		slice = reflect.AppendSlice(slice, field.Slice(0, i))
+		slice = reflect.AppendSlice(slice, field.Slice(i+1, length))
+		field.Set(slice)
+
+	case OpReplace:
+		i, err := index(length - 1)
+		if err != nil {
+			return err
+		}
+		elem, err := decodeElem()
+		if err != nil {
+			return err
+		}
+		c := copyValue(field)
+		if c.Kind() == reflect.Slice {
+			c = reflect.MakeSlice(field.Type(), length, length)
+			reflect.Copy(c, field)
+		}
+		c.Index(i).Set(elem)
+		field.Set(c)
+
+	default:
+		return fmt.Errorf("unsupported operation %q on slice", req.Op)
+	}
+	return nil
+}
+
+func modifyMap(field reflect.Value, req ModifyCollectionRequest, codecs *Codecs) error {
+	if req.Key == nil {
+		return fmt.Errorf("%s requires a key", req.Op)
+	}
+	key := reflect.New(field.Type().Key()).Elem()
+	if keyStr, ok := req.Key.(string); ok {
+		if err := decodeMapKey(key, keyStr); err != nil {
+			return err
+		}
+	} else if err := decodeValue(key, req.Key, codecs); err != nil {
+		return fmt.Errorf("invalid map key: %w", err)
+	}
+
+	m := reflect.MakeMapWithSize(field.Type(), field.Len()+1)
+	iter := field.MapRange()
+	for iter.Next() {
+		m.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	switch req.Op {
+	case OpSet:
+		elem := reflect.New(field.Type().Elem()).Elem()
+		if err := decodeValue(elem, req.Value, codecs); err != nil {
+			return fmt.Errorf("cannot decode value: %w", err)
+		}
+		m.SetMapIndex(key, elem)
+
+	case OpDelete:
+		if !m.MapIndex(key).IsValid() {
+			return errorWithStatus(http.StatusNotFound, fmt.Sprintf("Key %v not found", key))
+		}
+		m.SetMapIndex(key, reflect.Value{})
+
+	default:
+		return fmt.Errorf("unsupported operation %q on map", req.Op)
+	}
+
+	field.Set(m)
+	return nil
+}
+
+// req: PATCH /entities/3/components/PlayerData?field=Inventory
+// body: {"op": "append", "value": {"Name": "sword"}}
+// resp: {"value": [...], "type": "slice"}
+//
+// The response holds the value of the slice or map after the operation has been applied.
+// Elements holding pointers cost a walk of the whole world when encoding it, see [lazyLinkIndex].
+func (s *Server) modifyCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("entity_id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid entity ID", http.StatusBadRequest)
+		return
+	}
+
+	fieldPath := r.URL.Query().Get("field")
+	componentName := r.PathValue("component_name")
+
+	var req ModifyCollectionRequest
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var response ComponentResponse
+	err = s.execute(r.Context(), func() error {
+		entry := s.store.GetEntry(uint32(id))
+		if entry == nil {
+			return errorWithStatus(http.StatusNotFound, "Entity not found")
+		}
+
+		component, ok := findComponent(entry, componentName)
+		if !ok {
+			return errorWithStatus(http.StatusNotFound, "Component not found")
+		}
+		if err := s.policy.checkEditable(componentName, component.Type(), fieldPath); err != nil {
+			return err
+		}
+
+		field, err := findField(component, fieldPath)
+		if err != nil {
+			return err
+		}
+		var previous reflect.Value
+		if field.CanSet() {
+			previous = deepCopyValue(field)
+		}
+
+		if err := modifyCollection(component, fieldPath, req, s.codecs); err != nil {
+			return err
+		}
+		s.history.record(uint32(id), componentName, fieldPath, previous, deepCopyValue(field))
+
+		response, err = getComponentResponse(component, fieldPath, DefaultDepth, newLazyLinkIndex(s.store).owner(entry, componentName), s.codecs)
+		s.policy.lockFields(componentName, component.Type(), fieldPath, &response)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inventory struct {
+	Items  []string
+	Counts map[int]uint
+	Slots  [2]string
+}
+
+func TestModifyCollection_Slice(t *testing.T) {
+	inv := &inventory{Items: []string{"sword", "bow"}}
+	component := reflect.ValueOf(inv).Elem()
+	original := inv.Items
+	index := func(i int) *int { return &i }
+
+	require.NoError(t, ModifyCollection(component, "Items", ModifyCollectionRequest{Op: OpAppend, Value: "shield"}))
+	assert.Equal(t, []string{"sword", "bow", "shield"}, inv.Items)
+
+	require.NoError(t, ModifyCollection(component, "Items", ModifyCollectionRequest{Op: OpInsert, Index: index(0), Value: "axe"}))
+	assert.Equal(t, []string{"axe", "sword", "bow", "shield"}, inv.Items)
+
+	require.NoError(t, ModifyCollection(component, "Items", ModifyCollectionRequest{Op: OpRemove, Index: index(2)}))
+	assert.Equal(t, []string{"axe", "sword", "shield"}, inv.Items)
+
+	require.NoError(t, ModifyCollection(component, "Items", ModifyCollectionRequest{Op: OpReplace, Index: index(1), Value: "spear"}))
+	assert.Equal(t, []string{"axe", "spear", "shield"}, inv.Items)
+
+	require.NoError(t, ModifyCollection(component, "Slots", ModifyCollectionRequest{Op: OpReplace, Index: index(1), Value: "ring"}))
+	assert.Equal(t, [2]string{"", "ring"}, inv.Slots)
+
+	assert.Equal(t, []string{"sword", "bow"}, original, "original slice should be untouched")
+}
+
+func TestModifyCollection_Map(t *testing.T) {
+	inv := &inventory{}
+	component := reflect.ValueOf(inv).Elem()
+
+	require.NoError(t, ModifyCollection(component, "Counts", ModifyCollectionRequest{Op: OpSet, Key: "1", Value: 3.0}))
+	require.NoError(t, ModifyCollection(component, "Counts", ModifyCollectionRequest{Op: OpSet, Key: 2.0, Value: 5.0}))
+	assert.Equal(t, map[int]uint{1: 3, 2: 5}, inv.Counts)
+
+	original := inv.Counts
+	require.NoError(t, ModifyCollection(component, "Counts", ModifyCollectionRequest{Op: OpDelete, Key: "1"}))
+	assert.Equal(t, map[int]uint{2: 5}, inv.Counts)
+	assert.Equal(t, map[int]uint{1: 3, 2: 5}, original, "original map should be untouched")
+}
+
+func TestModifyCollection_Errors(t *testing.T) {
+	inv := &inventory{Items: []string{"sword"}, Counts: map[int]uint{1: 1}}
+	component := reflect.ValueOf(inv).Elem()
+	index := func(i int) *int { return &i }
+
+	tests := []struct {
+		name  string
+		field string
+		req   ModifyCollectionRequest
+		err   string
+	}{
+		{"index out of range", "Items", ModifyCollectionRequest{Op: OpRemove, Index: index(1)}, "index 1 out of range [0, 0]"},
+		{"missing index", "Items", ModifyCollectionRequest{Op: OpInsert, Value: "bow"}, "insert requires an index"},
+		{"element type", "Items", ModifyCollectionRequest{Op: OpAppend, Value: 1.0}, "cannot decode element: expected a string for string, got float64"},
+		{"map op on slice", "Items", ModifyCollectionRequest{Op: OpSet, Key: "a"}, `unsupported operation "set" on slice`},
+		{"array append", "Slots", ModifyCollectionRequest{Op: OpAppend, Value: "ring"}, "cannot append elements of array [2]string"},
+		{"invalid key", "Counts", ModifyCollectionRequest{Op: OpSet, Key: "one", Value: 1.0}, `invalid map key "one" for int`},
+		{"missing key", "Counts", ModifyCollectionRequest{Op: OpDelete, Key: "2"}, "Key 2 not found"},
+		{"not a collection", "Items[0]", ModifyCollectionRequest{Op: OpAppend}, "field of type string is not a slice or map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ModifyCollection(component, tt.field, tt.req)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -3,80 +3,62 @@ package server
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 func findField(component reflect.Value, fieldPath string) (reflect.Value, error) {
-	if fieldPath == "" {
-		return component, nil
+	segments, err := parseFieldPath(fieldPath)
+	if err != nil {
+		return reflect.Value{}, err
 	}
 
-	fields := strings.Split(fieldPath, ".")
-
-	for _, field := range fields {
-		// Process slice or map indexing while there are brackets []
-		for strings.Contains(field, "[") && strings.Contains(field, "]") {
-			fieldName := field[:strings.Index(field, "[")]
-			keyStr := field[strings.Index(field, "[")+1 : strings.Index(field, "]")]
-
-			// If there's a field name before [index], access it as a struct field
-			if fieldName != "" {
-				if component.Kind() == reflect.Ptr {
-					component = component.Elem()
-				}
-				if component.Kind() == reflect.Struct {
-					component = component.FieldByName(fieldName)
-				} else {
-					return reflect.Value{}, errors.New("invalid field access")
-				}
-			}
+	for i, segment := range segments {
+		// The component itself may be a pointer
+		if component.Kind() == reflect.Ptr && !component.IsNil() {
+			component = component.Elem()
+		}
 
-			// Handle slices
-			if component.Kind() == reflect.Slice {
-				index, err := strconv.Atoi(keyStr)
+		if segment.indexed {
+			// Handle slices and arrays
+			if component.Kind() == reflect.Slice || component.Kind() == reflect.Array {
+				index, err := strconv.Atoi(segment.name)
 				if err != nil || index < 0 || index >= component.Len() {
 					return reflect.Value{}, errors.New("invalid slice index")
 				}
 				component = component.Index(index)
 			} else if component.Kind() == reflect.Map {
-				// Handle maps with string keys
-				key := reflect.ValueOf(keyStr)
-				component = component.MapIndex(key)
-				if !component.IsValid() {
-					return reflect.Value{}, errors.New("invalid map key")
+				// Handle maps, parsing the key into the map's key type
+				component, err = mapIndex(component, segment.name)
+				if err != nil {
+					return reflect.Value{}, err
 				}
 			} else {
 				return reflect.Value{}, errors.New("invalid index access (not a slice or map)")
 			}
-
-			// Remove processed part from the field and check if there are more indices
-			field = field[strings.Index(field, "]")+1:]
-			if len(field) > 0 && field[0] == '.' {
-				field = field[1:] // Remove leading dot if present
-				break
-			}
-		}
-
-		// Handle struct field access
-		if field != "" {
-			if component.Kind() == reflect.Ptr && component.Elem().Kind() == reflect.Struct {
-				component = component.Elem()
-			}
+		} else {
+			// Handle struct field access
 			if component.Kind() == reflect.Struct {
-				component = component.FieldByName(field)
+				component = component.FieldByName(segment.name)
 			} else if component.Kind() == reflect.Map {
-				key := reflect.ValueOf(field)
-				component = component.MapIndex(key)
-				if !component.IsValid() {
-					return reflect.Value{}, errors.New("invalid map key")
+				component, err = mapIndex(component, segment.name)
+				if err != nil {
+					return reflect.Value{}, err
 				}
 			} else {
 				return reflect.Value{}, errors.New("invalid field access")
 			}
 		}
 
+		// Keep a nil pointer at the end of the path, so that SetField can allocate it.
+		if i == len(segments)-1 && component.Kind() == reflect.Ptr && component.IsNil() {
+			break
+		}
+
 		// Dereference pointers and interfaces
 		if component.Kind() == reflect.Interface || component.Kind() == reflect.Ptr {
 			component = component.Elem()
@@ -93,26 +75,239 @@ func findField(component reflect.Value, fieldPath string) (reflect.Value, error)
 	return component, nil
 }
 
+// pathSegment is a struct field name or map key following a dot, or a slice index or map key in brackets.
+type pathSegment struct {
+	name    string
+	indexed bool
+	// start is the offset of the segment in the path, including the dot or bracket before it.
+	start int
+}
+
+// parseFieldPath splits a field path such as "Items[1].Tags[a.b]" into its segments. Keys in brackets
+// may hold any character, and "]" and "\" are escaped with a backslash, see [IndexFieldPath].
+func parseFieldPath(fieldPath string) ([]pathSegment, error) {
+	var segments []pathSegment
+	invalid := fmt.Errorf("invalid field path %q", fieldPath)
+	for i := 0; i < len(fieldPath); {
+		start := i
+		switch {
+		case fieldPath[i] == '[':
+			var key strings.Builder
+			i++
+			for ; i < len(fieldPath) && fieldPath[i] != ']'; i++ {
+				if fieldPath[i] == '\\' && i+1 < len(fieldPath) {
+					i++
+				}
+				key.WriteByte(fieldPath[i])
+			}
+			if i == len(fieldPath) {
+				return nil, invalid
+			}
+			i++
+			segments = append(segments, pathSegment{name: key.String(), indexed: true, start: start})
+
+		case fieldPath[i] == '.' && len(segments) == 0:
+			return nil, invalid
+
+		default:
+			if fieldPath[i] == '.' {
+				i++
+			}
+			end := strings.IndexAny(fieldPath[i:], ".[")
+			if end < 0 {
+				end = len(fieldPath) - i
+			}
+			if end == 0 {
+				return nil, invalid
+			}
+			segments = append(segments, pathSegment{name: fieldPath[i : i+end], start: start})
+			i += end
+		}
+	}
+	return segments, nil
+}
+
+// mapIndex returns the value of the map at the key, which is parsed into the map's key type.
+func mapIndex(m reflect.Value, keyStr string) (reflect.Value, error) {
+	key := reflect.New(m.Type().Key()).Elem()
+	if err := decodeMapKey(key, keyStr); err != nil {
+		return reflect.Value{}, errors.New("invalid map key")
+	}
+	value := m.MapIndex(key)
+	if !value.IsValid() {
+		return reflect.Value{}, errors.New("invalid map key")
+	}
+	return value, nil
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// JoinFieldPath appends a struct field or map key to the path.
+// Structs and maps are both rendered as objects, so keys that are not
+// identifiers are assumed to be map keys and indexed with brackets.
+func JoinFieldPath(path string, key string) string {
+	if !identifierPattern.MatchString(key) {
+		return IndexFieldPath(path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+var keyEscaper = strings.NewReplacer(`\`, `\\`, `]`, `\]`)
+
+// IndexFieldPath appends a slice index or map key in brackets to the path, escaping the "]" and "\" in the key.
+func IndexFieldPath(path string, key string) string {
+	return path + "[" + keyEscaper.Replace(key) + "]"
+}
+
+const (
+	// DefaultDepth is the depth of values returned by [GetField]: the fields of a struct are
+	// returned, and their own fields are replaced by placeholders describing their kind.
+	DefaultDepth = 1
+	// FullDepth returns values in full. Values reached again through a pointer are returned as references, see [RefPath].
+	FullDepth = -1
+)
+
 func GetField(component reflect.Value, fieldPath string) (interface{}, error) {
-	field, err := findField(component, fieldPath)
+	response, err := getComponentResponse(component, fieldPath, DefaultDepth, nil, nil)
 	if err != nil {
 		return nil, err
 	}
+	return response.Value, nil
+}
+
+// getComponentResponse returns the value of the field at the given path up to depth, see [encodeField], with its type.
+// Pointers into other components are linked if links is not nil.
+func getComponentResponse(component reflect.Value, fieldPath string, depth int, links *componentLinks, codecs *Codecs) (ComponentResponse, error) {
+	field, err := findField(component, fieldPath)
+	if err != nil {
+		return ComponentResponse{}, err
+	}
 
-	fieldVal := recursivelyConstructValue(field, 1)
+	fieldVal := encodeField(field, fieldPath, depth, links, codecs)
 	if fieldVal == nil {
-		return nil, nil
+		return ComponentResponse{Type: ComponentTypeNil, Field: describeField(component, fieldPath)}, nil
 	}
 
 	// double quote string values
 	if str, ok := fieldVal.(string); ok {
-		return strconv.Quote(str), nil
+		fieldVal = strconv.Quote(str)
+	}
+
+	response := ComponentResponse{
+		Value: fieldVal,
+		Type:  reflectToComponentType(fieldVal),
+		Field: describeField(component, fieldPath),
+	}
+	// Values replaced by an encoder are edited as a whole, so their fields are not described.
+	if response.Type != ComponentTypePrimitive {
+		response.Fields = describeChildren(field)
+	}
+	if response.Type == ComponentTypeObject && reflect.Indirect(field).Kind() == reflect.Map {
+		response.Type = ComponentTypeMap
+	}
+	return response, nil
+}
+
+// describeField describes the field at the given path. Unlike [findField], it does not dereference
+// the field, so that pointers are described as such.
+func describeField(component reflect.Value, fieldPath string) FieldInfo {
+	if fieldPath == "" {
+		return newFieldInfo(component, "")
+	}
+
+	parentPath, key := splitFieldPath(fieldPath)
+	parent, err := findField(component, parentPath)
+	if err != nil || !parent.IsValid() {
+		return FieldInfo{}
 	}
+	parent = reflect.Indirect(parent)
 
-	return fieldVal, nil
+	switch parent.Kind() {
+	case reflect.Struct:
+		if structField, ok := parent.Type().FieldByName(key); ok {
+			return newFieldInfo(parent.FieldByIndex(structField.Index), structField.Tag)
+		}
+	case reflect.Slice, reflect.Array:
+		if index, err := strconv.Atoi(key); err == nil && index >= 0 && index < parent.Len() {
+			return newFieldInfo(parent.Index(index), "")
+		}
+	case reflect.Map:
+		if value, err := mapIndex(parent, key); err == nil {
+			return newFieldInfo(value, "")
+		}
+	}
+	return FieldInfo{}
 }
 
+// describeChildren describes the struct fields, slice elements or map entries of the value,
+// keyed like the value returned by [encodeField].
+func describeChildren(value reflect.Value) map[string]FieldInfo {
+	value = reflect.Indirect(value)
+	if !value.IsValid() {
+		return nil
+	}
+
+	var children map[string]FieldInfo
+	switch value.Kind() {
+	case reflect.Struct:
+		children = make(map[string]FieldInfo, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			structField := value.Type().Field(i)
+			children[structField.Name] = newFieldInfo(value.Field(i), structField.Tag)
+		}
+	case reflect.Slice, reflect.Array:
+		children = make(map[string]FieldInfo, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			children[strconv.Itoa(i)] = newFieldInfo(value.Index(i), "")
+		}
+	case reflect.Map:
+		children = make(map[string]FieldInfo, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			children[formatMapKey(iter.Key())] = newFieldInfo(iter.Value(), "")
+		}
+	}
+	return children
+}
+
+func newFieldInfo(value reflect.Value, tag reflect.StructTag) FieldInfo {
+	var nullable bool
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		nullable = true
+	}
+	return FieldInfo{
+		Type:     value.Type().String(),
+		Kind:     value.Kind().String(),
+		Nullable: nullable,
+		Settable: value.CanSet(),
+		Exported: value.CanInterface(),
+		Tag:      string(tag),
+	}
+}
+
+// splitFieldPath splits the last struct field, index or map key off the path, e.g.
+// "Items[1].X" into "Items[1]" and "X", or "Items[1]" into "Items" and "1".
+func splitFieldPath(fieldPath string) (parentPath string, key string) {
+	segments, err := parseFieldPath(fieldPath)
+	if err != nil || len(segments) == 0 {
+		return "", fieldPath
+	}
+	last := segments[len(segments)-1]
+	return fieldPath[:last.start], last.name
+}
+
+// SetField sets the field at the given path to the value, which is usually decoded from JSON.
+// The value is converted to the field's type, see [decodeValue].
 func SetField(component reflect.Value, fieldPath string, value interface{}) error {
+	return setField(component, fieldPath, value, nil)
+}
+
+// setField is [SetField], decoding the value with the decoders in codecs.
+func setField(component reflect.Value, fieldPath string, value interface{}, codecs *Codecs) error {
 	field, err := findField(component, fieldPath)
 	if err != nil {
 		return err
@@ -122,27 +317,79 @@ func SetField(component reflect.Value, fieldPath string, value interface{}) erro
 		return errors.New("field is not settable")
 	}
 
-	if field.Kind() == reflect.Slice || field.Kind() == reflect.Map {
-		return errors.New("cannot set value of slice or map directly")
+	// Check the value first, so the field and the values it points to are left untouched
+	// if decoding fails halfway through a struct.
+	if err := checkValue(field, value, codecs); err != nil {
+		return fmt.Errorf("cannot set field: %w", err)
 	}
-
-	if value == nil {
-		field.Set(reflect.Zero(field.Type()))
-		return nil
+	decoded := copyValue(field)
+	if err := decodeValue(decoded, value, codecs); err != nil {
+		return fmt.Errorf("cannot set field: %w", err)
 	}
+	field.Set(decoded)
+	return nil
+}
+
+// RefKey is the key of the objects that stand in for values encoded elsewhere, see [RefPath].
+const RefKey = "$ref"
 
-	val := reflect.ValueOf(value)
-	fieldType := field.Type()
-	if !val.Type().ConvertibleTo(fieldType) {
-		return fmt.Errorf("cannot set field: value type %s is not convertible to %s", val.Type(), fieldType)
+// RefPath returns the field path of the value referenced by an encoded value, if it is a reference.
+// Values reachable through several pointers are only encoded once, and the other occurrences are
+// replaced by {"$ref": "<path>"}, so that cycles do not recurse forever.
+func RefPath(value interface{}) (string, bool) {
+	obj, ok := value.(map[string]interface{})
+	if !ok || len(obj) != 1 {
+		return "", false
 	}
+	path, ok := obj[RefKey].(string)
+	return path, ok
+}
 
-	field.Set(val.Convert(fieldType))
-	return nil
+// encodeField converts the value at the path into JSON-serializable values, descending depth levels
+// into structs, slices and maps. Values below that depth are replaced by placeholders describing their kind.
+// A negative depth, such as [FullDepth], descends all the way. Values that were already encoded are
+// replaced by references, see [RefPath], and pointers into other components by links, see [LinkOf].
+// Values with an encoder in codecs, which may be nil, are replaced by their encoded value at any depth.
+func encodeField(value reflect.Value, path string, depth int, links *componentLinks, codecs *Codecs) interface{} {
+	e := fieldEncoder{paths: make(map[refKey]string), links: links, codecs: codecs}
+	return e.encode(value, path, depth)
+}
+
+// refKey identifies a value in memory. The type is part of the key, as a struct and its first field share their address.
+type refKey struct {
+	addr uintptr
+	typ  reflect.Type
+	len  int
 }
 
-func recursivelyConstructValue(value reflect.Value, depth int) interface{} {
-	if depth <= 0 {
+type fieldEncoder struct {
+	// paths holds the paths of the encoded values, by their key.
+	paths  map[refKey]string
+	links  *componentLinks
+	codecs *Codecs
+	// decodable leaves out the unexported fields and the values that cannot be represented in JSON,
+	// and keeps nil slices nil, so that the encoded value can be decoded back, see [encodeValue].
+	decodable bool
+}
+
+func (e *fieldEncoder) encode(value reflect.Value, path string, depth int) interface{} {
+	if link, ok := e.links.lookup(value); ok {
+		return link.encode()
+	}
+	if key, ok := refKeyOf(value); ok {
+		if refPath, ok := e.paths[key]; ok {
+			return map[string]interface{}{RefKey: refPath}
+		}
+		e.paths[key] = path
+	}
+	return e.encodeKind(value, path, depth)
+}
+
+func (e *fieldEncoder) encodeKind(value reflect.Value, path string, depth int) interface{} {
+	if encoded, ok := e.codecs.encode(value); ok {
+		return encoded
+	}
+	if depth == 0 {
 		if !value.IsValid() {
 			return nil
 		}
@@ -155,22 +402,53 @@ func recursivelyConstructValue(value reflect.Value, depth int) interface{} {
 	}
 	switch value.Kind() {
 	case reflect.Ptr:
-		return recursivelyConstructValue(value.Elem(), depth)
+		if value.IsNil() {
+			return nil
+		}
+		// The pointer has the key of the value it points to, so it is not looked up again.
+		return e.encodeKind(value.Elem(), path, depth)
+	case reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return e.encode(value.Elem(), path, depth)
 	case reflect.Struct:
 		fields := make(map[string]interface{})
 		for i := 0; i < value.NumField(); i++ {
-			field := value.Field(i)
-			fields[value.Type().Field(i).Name] = recursivelyConstructValue(field, depth-1)
+			field := value.Type().Field(i)
+			if e.decodable && (!field.IsExported() || !isEncodable(field.Type.Kind())) {
+				continue
+			}
+			fields[field.Name] = e.encode(value.Field(i), JoinFieldPath(path, field.Name), depth-1)
 		}
 		return fields
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
+		if e.decodable && value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
 		slice := make([]interface{}, value.Len())
 		for i := 0; i < value.Len(); i++ {
-			slice[i] = recursivelyConstructValue(value.Index(i), depth-1)
+			slice[i] = e.encode(value.Index(i), fmt.Sprintf("%s[%d]", path, i), depth-1)
 		}
 		return slice
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		// The entries are encoded in the order of their keys, so that the entries sharing a pointer
+		// are encoded in full and as references the same way every time.
+		keys := make(map[string]reflect.Value, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			keys[formatMapKey(iter.Key())] = iter.Key()
+		}
+		entries := make(map[string]interface{}, value.Len())
+		for _, key := range slices.Sorted(maps.Keys(keys)) {
+			entries[key] = e.encode(value.MapIndex(keys[key]), JoinFieldPath(path, key), depth-1)
+		}
+		return entries
 	default:
-		if !value.IsValid() {
+		if !value.IsValid() || e.decodable && !isEncodable(value.Kind()) {
 			return nil
 		}
 		if !value.CanInterface() {
@@ -179,3 +457,33 @@ func recursivelyConstructValue(value reflect.Value, depth int) interface{} {
 		return value.Interface()
 	}
 }
+
+// refKeyOf returns the key of the values that can be reached more than once: the values
+// of pointers, slices and maps, and addressable structs and arrays that pointers may point into.
+// Zero-sized values are skipped, as they may share their address with unrelated values.
+func refKeyOf(value reflect.Value) (refKey, bool) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() || value.Type().Elem().Size() == 0 {
+			return refKey{}, false
+		}
+		return refKey{addr: value.Pointer(), typ: value.Type().Elem()}, true
+	case reflect.Map:
+		if value.IsNil() {
+			return refKey{}, false
+		}
+		return refKey{addr: value.Pointer(), typ: value.Type()}, true
+	case reflect.Slice:
+		if value.Len() == 0 || value.Type().Elem().Size() == 0 {
+			return refKey{}, false
+		}
+		return refKey{addr: value.Pointer(), typ: value.Type(), len: value.Len()}, true
+	case reflect.Struct, reflect.Array:
+		if !value.CanAddr() || value.Type().Size() == 0 {
+			return refKey{}, false
+		}
+		return refKey{addr: value.UnsafeAddr(), typ: value.Type()}, true
+	default:
+		return refKey{}, false
+	}
+}
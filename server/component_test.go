@@ -1,8 +1,12 @@
 package server
 
 import (
+	"encoding/json"
+	"fmt"
+	"net"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -417,3 +421,371 @@ func TestRecursivelyFindField_MultipleIndicesAccess(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, float64(1), field)
 }
+
+func TestSetField_Coercion(t *testing.T) {
+	type Stats struct {
+		Health int
+		Speed  float32
+	}
+	type Player struct {
+		Level    uint8
+		Id       int64
+		Cooldown time.Duration
+		Address  net.IP
+		LastSeen time.Time
+		Stats    Stats
+		Pet      *Stats
+		Tags     []string
+		Data     []byte
+	}
+	player := &Player{Stats: Stats{Health: 10, Speed: 1}}
+	component := reflect.ValueOf(player).Elem()
+
+	assert.NoError(t, SetField(component, "Level", float64(3)))
+	assert.NoError(t, SetField(component, "Id", json.Number("9007199254740993")))
+	assert.NoError(t, SetField(component, "Cooldown", "1.5s"))
+	assert.NoError(t, SetField(component, "Address", "127.0.0.1"))
+	assert.NoError(t, SetField(component, "LastSeen", "2024-01-02T03:04:05Z"))
+	assert.NoError(t, SetField(component, "Stats", map[string]interface{}{"Health": json.Number("20")}))
+	assert.NoError(t, SetField(component, "Pet", map[string]interface{}{"Speed": 2.5}))
+	assert.NoError(t, SetField(component, "Tags", []interface{}{"a", "b"}))
+	assert.NoError(t, SetField(component, "Data", "raw"))
+
+	assert.Equal(t, &Player{
+		Level:    3,
+		Id:       9007199254740993,
+		Cooldown: 1500 * time.Millisecond,
+		Address:  net.IPv4(127, 0, 0, 1),
+		LastSeen: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Stats:    Stats{Health: 20, Speed: 1},
+		Pet:      &Stats{Speed: 2.5},
+		Tags:     []string{"a", "b"},
+		Data:     []byte("raw"),
+	}, player)
+}
+
+func TestSetField_CoercionErrors(t *testing.T) {
+	type Stats struct {
+		Health int8
+		Name   string
+	}
+	type Player struct {
+		Level    uint8
+		Cooldown time.Duration
+		Stats    Stats
+	}
+	player := &Player{Stats: Stats{Health: 10, Name: "tamago"}}
+	component := reflect.ValueOf(player).Elem()
+
+	err := SetField(component, "Level", float64(256))
+	assert.EqualError(t, err, "cannot set field: 256 overflows uint8")
+
+	err = SetField(component, "Level", 1.5)
+	assert.EqualError(t, err, "cannot set field: 1.5 is not an integer")
+
+	err = SetField(component, "Cooldown", "soon")
+	assert.EqualError(t, err, `cannot set field: invalid duration "soon"`)
+
+	err = SetField(component, "Stats", map[string]interface{}{"Name": "donburi", "Mana": 1.0})
+	assert.EqualError(t, err, `cannot set field: unknown field "Mana" in server.Stats`)
+
+	err = SetField(component, "Stats", map[string]interface{}{"Name": "donburi", "Health": 1000.0})
+	assert.EqualError(t, err, "cannot set field: Health: 1000 overflows int8")
+	assert.Equal(t, Stats{Health: 10, Name: "tamago"}, player.Stats, "field should be untouched after a failed set")
+}
+
+func TestSetField_FailedDecodeLeavesPointeeUntouched(t *testing.T) {
+	type Address struct {
+		City    string
+		Country *string
+	}
+	type Person struct {
+		Home *Address
+	}
+	country := "Japan"
+	shared := &Address{City: "Osaka", Country: &country}
+	person := Person{Home: shared}
+	component := reflect.ValueOf(&person).Elem()
+
+	err := SetField(component, "Home", map[string]interface{}{"City": "Kyoto", "Country": "Nihon", "Bogus": 1.0})
+	assert.EqualError(t, err, `cannot set field: unknown field "Bogus" in server.Address`)
+	assert.Equal(t, "Osaka", shared.City, "pointee should be untouched after a failed set")
+	assert.Equal(t, "Japan", country, "nested pointee should be untouched after a failed set")
+	assert.Same(t, shared, person.Home)
+
+	// Successful sets still update the value in place, for the other references to it.
+	assert.NoError(t, SetField(component, "Home", map[string]interface{}{"City": "Kyoto"}))
+	assert.Equal(t, "Kyoto", shared.City)
+	assert.Same(t, shared, person.Home)
+}
+
+type direction int
+
+func (d direction) String() string {
+	return [...]string{"Left", "Right"}[d]
+}
+
+type cellKey struct {
+	X, Y int
+}
+
+func (k cellKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", k.X, k.Y)), nil
+}
+
+func (k *cellKey) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "%d,%d", &k.X, &k.Y)
+	return err
+}
+
+func TestRecursivelyFindField_TypedMapKeys(t *testing.T) {
+	component := reflect.ValueOf(struct {
+		Speeds map[direction]float64
+		Cells  map[cellKey]string
+		Grid   map[int][2]int
+	}{
+		Speeds: map[direction]float64{0: 1.5, 1: 2.5},
+		Cells:  map[cellKey]string{{X: 1, Y: 2}: "wall"},
+		Grid:   map[int][2]int{3: {4, 5}},
+	})
+
+	field, err := GetField(component, "Speeds[1]")
+	assert.Nil(t, err)
+	assert.Equal(t, 2.5, field)
+
+	field, err = GetField(component, "Cells[1,2]")
+	assert.Nil(t, err)
+	assert.Equal(t, `"wall"`, field)
+
+	field, err = GetField(component, "Grid[3][1]")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, field)
+
+	_, err = GetField(component, "Speeds[Left]")
+	assert.EqualError(t, err, "invalid map key")
+
+	_, err = GetField(component, "Speeds[2]")
+	assert.EqualError(t, err, "invalid map key")
+}
+
+func TestGetField_Map(t *testing.T) {
+	component := reflect.ValueOf(struct {
+		Speeds map[direction]float64
+		Cells  map[cellKey]string
+	}{
+		Speeds: map[direction]float64{0: 1.5, 1: 2.5},
+		Cells:  map[cellKey]string{{X: 1, Y: 2}: "wall"},
+	})
+
+	response, err := getComponentResponse(component, "Speeds", DefaultDepth, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"0": "float64", "1": "float64"}, response.Value)
+	assert.Equal(t, ComponentTypeMap, response.Type)
+
+	field, err := GetField(component, "")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"Speeds": "map of server.direction to float64",
+		"Cells":  "map of server.cellKey to string",
+	}, field)
+
+	response, err = getComponentResponse(component, "Cells", DefaultDepth, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"1,2": "string"}, response.Value)
+}
+
+func TestEncodeField_Depth(t *testing.T) {
+	type Inner struct {
+		X int
+	}
+	component := reflect.ValueOf(struct {
+		Inner  Inner
+		Points []Inner
+	}{
+		Inner:  Inner{X: 1},
+		Points: []Inner{{X: 2}},
+	})
+
+	assert.Equal(t, "struct", encodeField(component, "", 0, nil, nil))
+	assert.Equal(t, map[string]interface{}{
+		"Inner":  "struct",
+		"Points": "slice of server.Inner",
+	}, encodeField(component, "", 1, nil, nil))
+	assert.Equal(t, map[string]interface{}{
+		"Inner":  map[string]interface{}{"X": "int"},
+		"Points": []interface{}{"struct"},
+	}, encodeField(component, "", 2, nil, nil))
+	assert.Equal(t, map[string]interface{}{
+		"Inner":  map[string]interface{}{"X": 1},
+		"Points": []interface{}{map[string]interface{}{"X": 2}},
+	}, encodeField(component, "", FullDepth, nil, nil))
+}
+
+func TestEncodeField_Cycle(t *testing.T) {
+	type Node struct {
+		Value int
+		Next  *Node
+	}
+	first := &Node{Value: 1}
+	first.Next = &Node{Value: 2, Next: first}
+
+	assert.Equal(t, map[string]interface{}{
+		"Value": 1,
+		"Next": map[string]interface{}{
+			"Value": 2,
+			"Next":  map[string]interface{}{RefKey: ""},
+		},
+	}, encodeField(reflect.ValueOf(first), "", FullDepth, nil, nil))
+
+	// Paths include the path of the encoded field, so they can be opened from any field.
+	encoded := encodeField(reflect.ValueOf(first), "Head", FullDepth, nil, nil).(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{RefKey: "Head"}, encoded["Next"].(map[string]interface{})["Next"])
+}
+
+func TestEncodeField_SharedPointers(t *testing.T) {
+	type Object struct {
+		X, Y float64
+	}
+	type Space struct {
+		Objects []Object
+	}
+	space := &Space{Objects: []Object{{X: 1}, {X: 2}}}
+	component := reflect.ValueOf(&struct {
+		Space    *Space
+		OnGround *Object
+		Nested   struct{ Space *Space }
+	}{
+		Space:    space,
+		OnGround: &space.Objects[1],
+	}).Elem()
+	component.FieldByName("Nested").Field(0).Set(reflect.ValueOf(space))
+
+	encoded := encodeField(component, "", FullDepth, nil, nil).(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{RefKey: "Space.Objects[1]"}, encoded["OnGround"])
+	assert.Equal(t, map[string]interface{}{"Space": map[string]interface{}{RefKey: "Space"}}, encoded["Nested"])
+
+	path, ok := RefPath(encoded["OnGround"])
+	assert.True(t, ok)
+	assert.Equal(t, "Space.Objects[1]", path)
+
+	_, ok = RefPath(map[string]interface{}{"X": 1.0})
+	assert.False(t, ok)
+}
+
+func TestEncodeField_SharedPointerInMap(t *testing.T) {
+	type Object struct {
+		X float64
+	}
+	shared := &Object{X: 1}
+	objects := map[string]*Object{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		objects[key] = shared
+	}
+	component := reflect.ValueOf(objects)
+
+	encoded := encodeField(component, "", FullDepth, nil, nil).(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"X": 1.0}, encoded["a"])
+	assert.Equal(t, map[string]interface{}{RefKey: "a"}, encoded["h"])
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, encoded, encodeField(component, "", FullDepth, nil, nil), "map entries should be encoded in the same order")
+	}
+}
+
+func TestGetComponentResponse_FieldInfo(t *testing.T) {
+	type Stats struct {
+		Speed float64 `json:"speed"`
+		level int
+	}
+	component := reflect.ValueOf(&struct {
+		Stats  *Stats
+		Scores map[string]int
+		Target interface{}
+	}{
+		Stats:  &Stats{Speed: 1.5},
+		Scores: map[string]int{"alice": 3},
+	}).Elem()
+
+	response, err := getComponentResponse(component, "Stats", DefaultDepth, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, FieldInfo{Type: "*server.Stats", Kind: "ptr", Nullable: true, Settable: true, Exported: true}, response.Field)
+	assert.Equal(t, map[string]FieldInfo{
+		"Speed": {Type: "float64", Kind: "float64", Settable: true, Exported: true, Tag: `json:"speed"`},
+		"level": {Type: "int", Kind: "int"},
+	}, response.Fields)
+
+	response, err = getComponentResponse(component, "Stats.Speed", DefaultDepth, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, FieldInfo{Type: "float64", Kind: "float64", Settable: true, Exported: true, Tag: `json:"speed"`}, response.Field)
+
+	response, err = getComponentResponse(component, "Scores[alice]", DefaultDepth, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, FieldInfo{Type: "int", Kind: "int", Exported: true}, response.Field)
+
+	response, err = getComponentResponse(component, "Target", DefaultDepth, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ComponentTypeNil, response.Type)
+	assert.Equal(t, FieldInfo{Type: "interface {}", Kind: "interface", Nullable: true, Settable: true, Exported: true}, response.Field)
+}
+
+func TestSplitFieldPath(t *testing.T) {
+	testCases := map[string][2]string{
+		"X":            {"", "X"},
+		"Position.X":   {"Position", "X"},
+		"Items[1]":     {"Items", "1"},
+		"Items[1].X":   {"Items[1]", "X"},
+		"Grid[3][1]":   {"Grid[3]", "1"},
+		"Cells[1,2].Y": {"Cells[1,2]", "Y"},
+		"M[1.5]":       {"M", "1.5"},
+		"M[a.b].X":     {"M[a.b]", "X"},
+		`M[a\]b]`:      {"M", "a]b"},
+	}
+	for path, expected := range testCases {
+		parentPath, key := splitFieldPath(path)
+		assert.Equal(t, expected, [2]string{parentPath, key}, path)
+	}
+}
+
+func TestRecursivelyFindField_KeysWithDots(t *testing.T) {
+	component := reflect.ValueOf(struct {
+		Weights map[float64]string
+		Hosts   map[string]int
+	}{
+		Weights: map[float64]string{1.5: "light"},
+		Hosts:   map[string]int{"example.com": 1, "a]b[c\\": 2},
+	})
+
+	testCases := []struct {
+		path     string
+		expected interface{}
+	}{
+		{JoinFieldPath("Weights", "1.5"), `"light"`},
+		{JoinFieldPath("Hosts", "example.com"), 1},
+		{JoinFieldPath("Hosts", "a]b[c\\"), 2},
+	}
+	for _, tc := range testCases {
+		field, err := GetField(component, tc.path)
+		assert.Nil(t, err, tc.path)
+		assert.Equal(t, tc.expected, field, tc.path)
+	}
+
+	_, err := GetField(component, "Hosts[example.com")
+	assert.EqualError(t, err, `invalid field path "Hosts[example.com"`)
+	_, err = GetField(component, "Hosts..X")
+	assert.EqualError(t, err, `invalid field path "Hosts..X"`)
+}
+
+func TestSetField_KeysWithDots(t *testing.T) {
+	type Stats struct {
+		Level int
+	}
+	component := reflect.ValueOf(&struct {
+		Players map[string]*Stats
+	}{
+		Players: map[string]*Stats{"player.one": {Level: 1}},
+	}).Elem()
+
+	path := JoinFieldPath(JoinFieldPath("Players", "player.one"), "Level")
+	assert.Equal(t, "Players[player.one].Level", path)
+	assert.NoError(t, SetField(component, path, 2.0))
+	assert.Equal(t, 2, component.Field(0).Interface().(map[string]*Stats)["player.one"].Level)
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+type CreateEntityRequest struct {
+	// Components are the names of the entity's components.
+	// They are resolved against the component types used by the world's archetypes first,
+	// then against all other component types created by the program.
+	Components []string `json:"components"`
+	// Values optionally holds initial field values, keyed by component name and field path.
+	Values map[string]map[string]interface{} `json:"values,omitempty"`
+}
+
+// req: POST /entities
+// body: {"components": ["Object", "Player"], "values": {"Object": {"X": 10}}}
+// resp: 201, {"entity": {...}}
+func (s *Server) createEntityHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateEntityRequest
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Components) == 0 {
+		http.Error(w, "An entity must have at least one component", http.StatusBadRequest)
+		return
+	}
+	for componentName := range req.Values {
+		if !slices.Contains(req.Components, componentName) {
+			http.Error(w, fmt.Sprintf("Value given for missing component %q", componentName), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, componentName := range req.Components {
+		if err := s.policy.checkEditable(componentName, nil, ""); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	var response GetEntityResponse
+	err := s.execute(r.Context(), func() error {
+		world := s.store.GetWorld()
+		componentTypes, err := newComponentRegistry(world).lookupAll(req.Components)
+		if err != nil {
+			return err
+		}
+
+		entry := world.Entry(world.Create(componentTypes...))
+		for componentName, fields := range req.Values {
+			component, _ := findComponent(entry, componentName)
+			for fieldPath, value := range fields {
+				if err := setField(component, fieldPath, value, s.codecs); err != nil {
+					s.removeEntry(entry)
+					return fmt.Errorf("setting %s.%s: %w", componentName, fieldPath, err)
+				}
+			}
+		}
+		s.store.AddEntry(entry)
+
+		response = GetEntityResponse{Entity: entityFromEntry(entry, s.codecs)}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,426 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// decodeValue sets the target to the JSON-decoded value (nil, bool, float64 or [json.Number], string, []interface{}
+// or map[string]interface{}), converting it to the target's type and allocating pointers, slices and maps as needed.
+// Go values that are assignable to the target, and Go numbers, are accepted as well.
+//
+// Structs are decoded from objects keyed by exported field name, leaving the fields that are not in the object untouched.
+// Types implementing [json.Unmarshaler] or [encoding.TextUnmarshaler] decode themselves, [time.Duration]
+// can also be decoded from a string such as "1.5s", and byte slices from a string holding the raw bytes. A nil value resets the target to its zero value.
+// Types with a decoder in codecs, which may be nil, are decoded by it.
+//
+// Values the target points to are decoded in place, and may be left partially decoded if decoding fails,
+// see [checkValue].
+func decodeValue(target reflect.Value, value interface{}, codecs *Codecs) error {
+	return decoder{codecs: codecs}.decode(target, value, "")
+}
+
+// checkValue returns the error [decodeValue] would return, without modifying the target or the values it points to.
+func checkValue(target reflect.Value, value interface{}, codecs *Codecs) error {
+	return decoder{codecs: codecs, detached: true}.decode(copyValue(target), value, "")
+}
+
+// decodeSnapshotValue is [decodeValue] for the values encoded by [encodeValue], which may hold references
+// to other values of the target, see [RefPath]. Pointers are set to the value they reference, and other
+// values to a copy of it, sharing the same elements in the case of slices and maps.
+func decodeSnapshotValue(target reflect.Value, value interface{}, codecs *Codecs) error {
+	var refs []pendingRef
+	if err := (decoder{codecs: codecs, refs: &refs}).decode(target, value, ""); err != nil {
+		return err
+	}
+
+	// The referenced values are always encoded in full, so they are decoded by now.
+	for _, ref := range refs {
+		referenced, err := findField(target, ref.refPath)
+		if err != nil || !referenced.IsValid() {
+			return decodeErrorf(ref.path, "invalid reference to %q", ref.refPath)
+		}
+		switch {
+		case ref.target.Kind() == reflect.Ptr && referenced.Type() == ref.target.Type().Elem():
+			if !referenced.CanAddr() {
+				copied := reflect.New(referenced.Type())
+				copied.Elem().Set(referenced)
+				referenced = copied.Elem()
+			}
+			ref.target.Set(referenced.Addr())
+		case referenced.Type().AssignableTo(ref.target.Type()):
+			ref.target.Set(referenced)
+		default:
+			return decodeErrorf(ref.path, "cannot decode a reference to %s into %s", referenced.Type(), ref.target.Type())
+		}
+	}
+	return nil
+}
+
+type decoder struct {
+	codecs *Codecs
+	// detached decodes into copies of the values pointed to, instead of in place.
+	detached bool
+	// refs collects the references to resolve once the whole value is decoded, if not nil.
+	refs *[]pendingRef
+}
+
+// pendingRef is a reference to the value at refPath, to be decoded into the target.
+type pendingRef struct {
+	target  reflect.Value
+	path    string
+	refPath string
+}
+
+func (d decoder) decode(target reflect.Value, value interface{}, path string) error {
+	if !target.CanSet() {
+		return decodeErrorf(path, "field is not settable")
+	}
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+	if refPath, ok := RefPath(value); ok && d.refs != nil {
+		*d.refs = append(*d.refs, pendingRef{target: target, path: path, refPath: refPath})
+		return nil
+	}
+
+	if val := reflect.ValueOf(value); val.Type().AssignableTo(target.Type()) && !isJSONValue(value) {
+		target.Set(val)
+		return nil
+	}
+	if decoder, ok := d.codecs.decoder(target.Type(), value); ok {
+		decoded, err := decoder(value)
+		if err != nil {
+			return decodeErrorf(path, "%v", err)
+		}
+		target.Set(decoded)
+		return nil
+	}
+	if ok, err := decodeUnmarshaler(target, value, path); ok {
+		return err
+	}
+	if target.Type() == durationType {
+		if str, ok := value.(string); ok {
+			d, err := time.ParseDuration(str)
+			if err != nil {
+				return decodeErrorf(path, "invalid duration %q", str)
+			}
+			target.SetInt(int64(d))
+			return nil
+		}
+	}
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		// Decode in place, so other references to the pointed-to value see the change.
+		if !target.IsNil() && !d.detached {
+			return d.decode(target.Elem(), value, path)
+		}
+		elem := reflect.New(target.Type().Elem())
+		if !target.IsNil() {
+			elem.Elem().Set(target.Elem())
+		}
+		if err := d.decode(elem.Elem(), value, path); err != nil {
+			return err
+		}
+		target.Set(elem)
+		return nil
+
+	case reflect.Interface:
+		val := reflect.ValueOf(plainJSONValue(value))
+		if !val.Type().AssignableTo(target.Type()) {
+			return decodeErrorf(path, "cannot decode %T into %s", value, target.Type())
+		}
+		target.Set(val)
+		return nil
+
+	case reflect.Struct:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return decodeErrorf(path, "expected an object for %s, got %T", target.Type(), value)
+		}
+		for name, fieldValue := range fields {
+			field, ok := target.Type().FieldByName(name)
+			if !ok || !field.IsExported() {
+				return decodeErrorf(path, "unknown field %q in %s", name, target.Type())
+			}
+			fieldTarget, err := target.FieldByIndexErr(field.Index)
+			if err != nil {
+				return decodeErrorf(path, "%v", err)
+			}
+			if err := d.decode(fieldTarget, fieldValue, joinPath(path, name)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Slice:
+		if str, ok := value.(string); ok && target.Type().Elem().Kind() == reflect.Uint8 {
+			target.SetBytes([]byte(str))
+			return nil
+		}
+		elems, ok := value.([]interface{})
+		if !ok {
+			return decodeErrorf(path, "expected an array for %s, got %T", target.Type(), value)
+		}
+		slice := reflect.MakeSlice(target.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := d.decode(slice.Index(i), elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+		return nil
+
+	case reflect.Array:
+		elems, ok := value.([]interface{})
+		if !ok {
+			return decodeErrorf(path, "expected an array for %s, got %T", target.Type(), value)
+		}
+		if len(elems) != target.Len() {
+			return decodeErrorf(path, "expected %d elements for %s, got %d", target.Len(), target.Type(), len(elems))
+		}
+		for i, elem := range elems {
+			if err := d.decode(target.Index(i), elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return decodeErrorf(path, "expected an object for %s, got %T", target.Type(), value)
+		}
+		m := reflect.MakeMapWithSize(target.Type(), len(entries))
+		for keyStr, entry := range entries {
+			key := reflect.New(target.Type().Key()).Elem()
+			if err := decodeMapKey(key, keyStr); err != nil {
+				return decodeErrorf(path, "%v", err)
+			}
+			elem := reflect.New(target.Type().Elem()).Elem()
+			if err := d.decode(elem, entry, fmt.Sprintf("%s[%s]", path, keyStr)); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		target.Set(m)
+		return nil
+
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return decodeErrorf(path, "expected a bool for %s, got %T", target.Type(), value)
+		}
+		target.SetBool(b)
+		return nil
+
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return decodeErrorf(path, "expected a string for %s, got %T", target.Type(), value)
+		}
+		target.SetString(s)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := decodeNumber(value, target.Type(), path)
+		if err != nil {
+			return err
+		}
+		if !n.IsInt() {
+			return decodeErrorf(path, "%v is not an integer", value)
+		}
+		if !n.Num().IsInt64() || target.OverflowInt(n.Num().Int64()) {
+			return decodeErrorf(path, "%v overflows %s", value, target.Type())
+		}
+		target.SetInt(n.Num().Int64())
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := decodeNumber(value, target.Type(), path)
+		if err != nil {
+			return err
+		}
+		if !n.IsInt() {
+			return decodeErrorf(path, "%v is not an integer", value)
+		}
+		if !n.Num().IsUint64() || target.OverflowUint(n.Num().Uint64()) {
+			return decodeErrorf(path, "%v overflows %s", value, target.Type())
+		}
+		target.SetUint(n.Num().Uint64())
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		n, err := decodeNumber(value, target.Type(), path)
+		if err != nil {
+			return err
+		}
+		f, _ := n.Float64()
+		if math.IsInf(f, 0) || target.OverflowFloat(f) {
+			return decodeErrorf(path, "%v overflows %s", value, target.Type())
+		}
+		target.SetFloat(f)
+		return nil
+
+	default:
+		return decodeErrorf(path, "cannot decode into %s", target.Type())
+	}
+}
+
+// isJSONValue returns true if the value may hold [json.Number]s, which have to be decoded.
+func isJSONValue(value interface{}) bool {
+	switch value.(type) {
+	case json.Number, []interface{}, map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+// plainJSONValue replaces the [json.Number]s in the value with float64s,
+// as if the value had been decoded without [json.Decoder.UseNumber].
+func plainJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v
+		}
+		return f
+	case []interface{}:
+		elems := make([]interface{}, len(v))
+		for i, elem := range v {
+			elems[i] = plainJSONValue(elem)
+		}
+		return elems
+	case map[string]interface{}:
+		entries := make(map[string]interface{}, len(v))
+		for key, entry := range v {
+			entries[key] = plainJSONValue(entry)
+		}
+		return entries
+	}
+	return value
+}
+
+// decodeUnmarshaler decodes the value using the target's [json.Unmarshaler] or [encoding.TextUnmarshaler]
+// implementation, if there is one. It returns false if the target implements neither.
+func decodeUnmarshaler(target reflect.Value, value interface{}, path string) (bool, error) {
+	if target.Kind() == reflect.Ptr || !target.CanAddr() {
+		return false, nil
+	}
+
+	switch unmarshaler := target.Addr().Interface().(type) {
+	case json.Unmarshaler:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return true, decodeErrorf(path, "%v", err)
+		}
+		if err := unmarshaler.UnmarshalJSON(b); err != nil {
+			return true, decodeErrorf(path, "invalid %s: %v", target.Type(), err)
+		}
+		return true, nil
+	case encoding.TextUnmarshaler:
+		str, ok := value.(string)
+		if !ok {
+			return true, decodeErrorf(path, "expected a string for %s, got %T", target.Type(), value)
+		}
+		if err := unmarshaler.UnmarshalText([]byte(str)); err != nil {
+			return true, decodeErrorf(path, "invalid %s: %v", target.Type(), err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// decodeNumber returns the exact value of a JSON number ([json.Number] or float64) or of a Go number.
+func decodeNumber(value interface{}, typ reflect.Type, path string) (*big.Rat, error) {
+	var n *big.Rat
+	switch v := value.(type) {
+	case json.Number:
+		n, _ = new(big.Rat).SetString(string(v))
+	case float64:
+		n = new(big.Rat).SetFloat64(v)
+	default:
+		val := reflect.ValueOf(value)
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = new(big.Rat).SetInt64(val.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n = new(big.Rat).SetUint64(val.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = new(big.Rat).SetFloat64(val.Float())
+		default:
+			return nil, decodeErrorf(path, "expected a number for %s, got %T", typ, value)
+		}
+	}
+	if n == nil {
+		return nil, decodeErrorf(path, "invalid number %v for %s", value, typ)
+	}
+	return n, nil
+}
+
+// decodeMapKey parses a map key formatted by [formatMapKey].
+func decodeMapKey(key reflect.Value, keyStr string) error {
+	if unmarshaler, ok := key.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(keyStr)); err != nil {
+			return fmt.Errorf("invalid map key %q for %s", keyStr, key.Type())
+		}
+		return nil
+	}
+
+	switch key.Kind() {
+	case reflect.String:
+		key.SetString(keyStr)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(keyStr)
+		if err != nil {
+			return fmt.Errorf("invalid map key %q for %s", keyStr, key.Type())
+		}
+		key.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(keyStr, 10, key.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid map key %q for %s", keyStr, key.Type())
+		}
+		key.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(keyStr, 10, key.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid map key %q for %s", keyStr, key.Type())
+		}
+		key.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(keyStr, key.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid map key %q for %s", keyStr, key.Type())
+		}
+		key.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported map key type %s", key.Type())
+	}
+	return nil
+}
+
+func decodeErrorf(path string, format string, args ...interface{}) error {
+	if path == "" {
+		return fmt.Errorf(format, args...)
+	}
+	return fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...))
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
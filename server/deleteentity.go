@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/yohamta/donburi"
+)
+
+// req: DELETE /entities/3
+// resp: 204
+func (s *Server) deleteEntityHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid entity ID", http.StatusBadRequest)
+		return
+	}
+
+	err = s.execute(r.Context(), func() error {
+		entry := s.store.GetEntry(uint32(id))
+		if entry == nil || !entry.Valid() {
+			return errorWithStatus(http.StatusNotFound, "Entity not found")
+		}
+		for _, componentType := range entry.Archetype().ComponentTypes() {
+			if err := s.policy.checkEditable(componentType.Name(), componentType.Typ(), ""); err != nil {
+				return err
+			}
+		}
+
+		s.removeEntry(entry)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeEntry removes the entry from the world and the store.
+func (s *Server) removeEntry(entry *donburi.Entry) {
+	id := entry.Id()
+	s.store.GetWorld().Remove(entry.Entity())
+	s.store.RemoveEntry(uint32(id))
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// SnapshotDiff lists the differences between two snapshots.
+type SnapshotDiff struct {
+	Added   []SnapshotEntity `json:"added"`
+	Removed []SnapshotEntity `json:"removed"`
+	Changed []EntityDiff     `json:"changed"`
+}
+
+// EntityDiff lists the differences of an entity that is in both snapshots.
+type EntityDiff struct {
+	Id                string        `json:"id"`
+	AddedComponents   []string      `json:"added_components,omitempty"`
+	RemovedComponents []string      `json:"removed_components,omitempty"`
+	Fields            []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a changed value of a component, identified by a field path that [GetField] understands.
+type FieldChange struct {
+	Component string      `json:"component"`
+	Field     string      `json:"field"`
+	Type      ChangeType  `json:"type"`
+	From      interface{} `json:"from"`
+	To        interface{} `json:"to"`
+}
+
+type DiffSnapshotRequest struct {
+	From Snapshot `json:"from"`
+	// To is compared to From. If nil, the live world is used instead.
+	To *Snapshot `json:"to,omitempty"`
+}
+
+// IsEmpty returns true if the snapshots are equal.
+func (d SnapshotDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// req: POST /snapshot/diff
+// body: {"from": {"version": 1, "entities": [...]}, "to": {"version": 1, "entities": [...]}}
+// resp: {"added": [...], "removed": [...], "changed": [{"id": "1", "fields": [...]}]}
+func (s *Server) diffSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request DiffSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var to Snapshot
+	if request.To != nil {
+		to = *request.To
+	} else {
+		err := s.execute(r.Context(), func() error {
+			to = takeSnapshot(s.store.GetWorld(), s.codecs)
+			return nil
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	response, err := DiffSnapshots(request.From, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DiffSnapshots compares the entities of two snapshots by ID.
+func DiffSnapshots(from, to Snapshot) (SnapshotDiff, error) {
+	for _, snapshot := range []Snapshot{from, to} {
+		if snapshot.Version != SnapshotVersion {
+			return SnapshotDiff{}, fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, SnapshotVersion)
+		}
+	}
+	var err error
+	if from, err = normalizeSnapshot(from); err != nil {
+		return SnapshotDiff{}, err
+	}
+	if to, err = normalizeSnapshot(to); err != nil {
+		return SnapshotDiff{}, err
+	}
+
+	diff := SnapshotDiff{
+		Added:   []SnapshotEntity{},
+		Removed: []SnapshotEntity{},
+		Changed: []EntityDiff{},
+	}
+
+	fromEntities := make(map[string]SnapshotEntity, len(from.Entities))
+	for _, entity := range from.Entities {
+		fromEntities[entity.Id] = entity
+	}
+	toEntities := make(map[string]SnapshotEntity, len(to.Entities))
+	for _, entity := range to.Entities {
+		toEntities[entity.Id] = entity
+		if _, ok := fromEntities[entity.Id]; !ok {
+			diff.Added = append(diff.Added, entity)
+		}
+	}
+
+	for _, entity := range from.Entities {
+		toEntity, ok := toEntities[entity.Id]
+		if !ok {
+			diff.Removed = append(diff.Removed, entity)
+			continue
+		}
+		if entityDiff := diffEntities(entity, toEntity); entityDiff != nil {
+			diff.Changed = append(diff.Changed, *entityDiff)
+		}
+	}
+
+	byId := func(a, b string) int {
+		idA, _ := strconv.Atoi(a)
+		idB, _ := strconv.Atoi(b)
+		return cmp.Compare(idA, idB)
+	}
+	slices.SortFunc(diff.Added, func(a, b SnapshotEntity) int { return byId(a.Id, b.Id) })
+	slices.SortFunc(diff.Removed, func(a, b SnapshotEntity) int { return byId(a.Id, b.Id) })
+	slices.SortFunc(diff.Changed, func(a, b EntityDiff) int { return byId(a.Id, b.Id) })
+	return diff, nil
+}
+
+// diffEntities returns nil if the entities are equal.
+func diffEntities(from, to SnapshotEntity) *EntityDiff {
+	diff := EntityDiff{Id: from.Id}
+	for _, name := range to.Archetype {
+		if !slices.Contains(from.Archetype, name) {
+			diff.AddedComponents = append(diff.AddedComponents, name)
+		}
+	}
+	for _, name := range from.Archetype {
+		if !slices.Contains(to.Archetype, name) {
+			diff.RemovedComponents = append(diff.RemovedComponents, name)
+		}
+	}
+
+	for _, name := range sortedKeys(from.Components) {
+		toValue, ok := to.Components[name]
+		if !ok {
+			continue
+		}
+		diffValues(from.Components[name], toValue, func(path string, changeType ChangeType, fromValue, toValue interface{}) {
+			diff.Fields = append(diff.Fields, FieldChange{
+				Component: name,
+				Field:     path,
+				Type:      changeType,
+				From:      fromValue,
+				To:        toValue,
+			})
+		})
+	}
+
+	if len(diff.AddedComponents) == 0 && len(diff.RemovedComponents) == 0 && len(diff.Fields) == 0 {
+		return nil
+	}
+	return &diff
+}
+
+// diffValues walks two encoded values and reports every leaf that differs.
+func diffValues(from, to interface{}, report func(path string, changeType ChangeType, from, to interface{})) {
+	var walk func(path string, from, to interface{})
+	walk = func(path string, from, to interface{}) {
+		switch fromValue := from.(type) {
+		case map[string]interface{}:
+			toValue, ok := to.(map[string]interface{})
+			if !ok {
+				break
+			}
+			for _, key := range sortedKeys(fromValue) {
+				if _, ok := toValue[key]; !ok {
+					report(JoinFieldPath(path, key), ChangeRemoved, fromValue[key], nil)
+					continue
+				}
+				walk(JoinFieldPath(path, key), fromValue[key], toValue[key])
+			}
+			for _, key := range sortedKeys(toValue) {
+				if _, ok := fromValue[key]; !ok {
+					report(JoinFieldPath(path, key), ChangeAdded, nil, toValue[key])
+				}
+			}
+			return
+
+		case []interface{}:
+			toValue, ok := to.([]interface{})
+			if !ok {
+				break
+			}
+			for i := 0; i < max(len(fromValue), len(toValue)); i++ {
+				elemPath := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(toValue):
+					report(elemPath, ChangeRemoved, fromValue[i], nil)
+				case i >= len(fromValue):
+					report(elemPath, ChangeAdded, nil, toValue[i])
+				default:
+					walk(elemPath, fromValue[i], toValue[i])
+				}
+			}
+			return
+		}
+
+		if !reflect.DeepEqual(from, to) {
+			report(path, ChangeChanged, from, to)
+		}
+	}
+	walk("", from, to)
+}
+
+// normalizeSnapshot returns the snapshot as decoded from JSON, so that the values taken from the world,
+// e.g. ints and named types, compare equal to the same values read from a snapshot file, where numbers are float64.
+func normalizeSnapshot(snapshot Snapshot) (Snapshot, error) {
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("encoding snapshot: %w", err)
+	}
+	var normalized Snapshot
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return Snapshot{}, fmt.Errorf("decoding snapshot: %w", err)
+	}
+	return normalized, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yohamta/donburi"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	from := Snapshot{
+		Version: SnapshotVersion,
+		Entities: []SnapshotEntity{
+			{
+				Id:        "1",
+				Archetype: []string{"Object", "Player"},
+				Components: map[string]interface{}{
+					"Object": map[string]interface{}{
+						"X":      1.0,
+						"Points": []interface{}{1.0, 2.0},
+						"Items":  map[string]interface{}{"sword": 1.0, "bow 2": 1.0},
+					},
+				},
+			},
+			{Id: "2", Archetype: []string{"Wall"}},
+			{Id: "3", Archetype: []string{"Wall"}},
+		},
+	}
+	to := Snapshot{
+		Version: SnapshotVersion,
+		Entities: []SnapshotEntity{
+			{
+				Id:        "1",
+				Archetype: []string{"Object", "Tween"},
+				Components: map[string]interface{}{
+					"Object": map[string]interface{}{
+						"X":      2.0,
+						"Points": []interface{}{1.0},
+						"Items":  map[string]interface{}{"sword": 1.0, "bow 2": 3.0, "shield": nil},
+					},
+				},
+			},
+			{Id: "3", Archetype: []string{"Wall"}},
+			{Id: "4", Archetype: []string{"Wall"}},
+		},
+	}
+
+	diff, err := DiffSnapshots(from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, SnapshotDiff{
+		Added:   []SnapshotEntity{{Id: "4", Archetype: []string{"Wall"}}},
+		Removed: []SnapshotEntity{{Id: "2", Archetype: []string{"Wall"}}},
+		Changed: []EntityDiff{
+			{
+				Id:                "1",
+				AddedComponents:   []string{"Tween"},
+				RemovedComponents: []string{"Player"},
+				Fields: []FieldChange{
+					{Component: "Object", Field: "Items[bow 2]", Type: ChangeChanged, From: 1.0, To: 3.0},
+					{Component: "Object", Field: "Items.shield", Type: ChangeAdded, From: nil, To: nil},
+					{Component: "Object", Field: "Points[1]", Type: ChangeRemoved, From: 2.0, To: nil},
+					{Component: "Object", Field: "X", Type: ChangeChanged, From: 1.0, To: 2.0},
+				},
+			},
+		},
+	}, diff)
+}
+
+func TestDiffSnapshots_Equal(t *testing.T) {
+	snapshot := Snapshot{
+		Version: SnapshotVersion,
+		Entities: []SnapshotEntity{
+			{Id: "1", Archetype: []string{"Object"}, Components: map[string]interface{}{"Object": map[string]interface{}{"X": 1.0}}},
+		},
+	}
+
+	diff, err := DiffSnapshots(snapshot, snapshot)
+	require.NoError(t, err)
+	assert.True(t, diff.IsEmpty())
+}
+
+type difficulty int
+
+func TestDiffSnapshots_DecodedBaseline(t *testing.T) {
+	type Stats struct {
+		X          int
+		Kills      uint16
+		Difficulty difficulty
+		Scores     []int
+		Name       string
+	}
+	world := donburi.NewWorld()
+	statsComponent := donburi.NewComponentType[Stats](Stats{X: 3, Kills: 7, Difficulty: 2, Scores: []int{1, 2}, Name: "tamago"})
+	statsComponent.SetName("Stats")
+	world.Create(statsComponent)
+
+	// The baseline is read from a file, or sent by the client, while the world is encoded as is.
+	b, err := json.Marshal(takeSnapshot(world, nil))
+	require.NoError(t, err)
+	var baseline Snapshot
+	require.NoError(t, json.Unmarshal(b, &baseline))
+
+	diff, err := DiffSnapshots(baseline, takeSnapshot(world, nil))
+	require.NoError(t, err)
+	assert.True(t, diff.IsEmpty(), "unchanged world should not differ: %+v", diff)
+}
+
+func TestDiffSnapshots_UnsupportedVersion(t *testing.T) {
+	_, err := DiffSnapshots(Snapshot{Version: SnapshotVersion}, Snapshot{Version: 0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeDiscoveryFile writes the address to the file, replacing it atomically so that
+// clients never read a partially written address.
+func writeDiscoveryFile(path string, addr string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(addr + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadDiscoveryFile returns the address written to the file by a running server, see [Config.DiscoveryFile].
+func ReadDiscoveryFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	addr := strings.TrimSpace(string(b))
+	if addr == "" {
+		return "", fmt.Errorf("discovery file %s is empty", path)
+	}
+	return addr, nil
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// editPolicy decides which fields clients may edit.
+//
+// The allow and deny lists hold component names, e.g. "Player", or field paths prefixed by the component name,
+// e.g. "Player.Health" or "Object.Points[0]", which cover the fields they hold too.
+type editPolicy struct {
+	readOnly bool
+	// allow lists the only editable fields, if not empty.
+	allow []string
+	// deny lists the fields that cannot be edited, even if they are allowed.
+	deny []string
+}
+
+// restricted reports whether some fields cannot be edited.
+func (p editPolicy) restricted() bool {
+	return p.readOnly || len(p.allow) > 0 || len(p.deny) > 0
+}
+
+// editable reports whether the field of the component can be edited. Setting a field replaces the fields it holds,
+// so it is locked if one of them is denied. An empty field path stands for the whole component.
+//
+// The paths are compared by segment, so that the spellings [findField] accepts for the same field, e.g. "Items.sword"
+// and "Items[sword]", or "Scores[01]" and "Scores[1]" for an int key, compare equal. Keys and indices are parsed
+// with the component type, which may be nil to compare them as written.
+func (p editPolicy) editable(componentName string, componentType reflect.Type, fieldPath string) bool {
+	if p.readOnly {
+		return false
+	}
+
+	target, err := normalizeFieldPath(componentType, fieldPath)
+	if err != nil {
+		return false
+	}
+
+	for _, denied := range p.deny {
+		if path, ok := patternPath(denied, componentName, componentType); ok && (coversPath(path, target) || coversPath(target, path)) {
+			return false
+		}
+	}
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, allowed := range p.allow {
+		if path, ok := patternPath(allowed, componentName, componentType); ok && coversPath(path, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEditable returns a 403 error if the field of the component cannot be edited.
+func (p editPolicy) checkEditable(componentName string, componentType reflect.Type, fieldPath string) error {
+	if p.editable(componentName, componentType, fieldPath) {
+		return nil
+	}
+	if fieldPath == "" {
+		return errorWithStatus(http.StatusForbidden, fmt.Sprintf("Component %s is locked", componentName))
+	}
+	return errorWithStatus(http.StatusForbidden, fmt.Sprintf("Field %s of %s is locked", fieldPath, componentName))
+}
+
+// lockFields marks the field of the response, and the fields it holds, as locked if they cannot be edited.
+// The fields are checked at the paths clients edit them with.
+func (p editPolicy) lockFields(componentName string, componentType reflect.Type, fieldPath string, response *ComponentResponse) {
+	if !p.restricted() {
+		return
+	}
+	response.Field.Locked = !p.editable(componentName, componentType, fieldPath)
+	for key, info := range response.Fields {
+		path := JoinFieldPath(fieldPath, key)
+		if response.Type == ComponentTypeSlice || response.Type == ComponentTypeMap {
+			path = IndexFieldPath(fieldPath, key)
+		}
+		info.Locked = !p.editable(componentName, componentType, path)
+		response.Fields[key] = info
+	}
+}
+
+// patternPath returns the segments of the field path of an allow or deny list entry, if it applies to the component.
+func patternPath(pattern string, componentName string, componentType reflect.Type) ([]string, bool) {
+	rest, ok := strings.CutPrefix(pattern, componentName)
+	if !ok {
+		return nil, false
+	}
+	if strings.HasPrefix(rest, ".") {
+		rest = rest[1:]
+	} else if rest != "" && !strings.HasPrefix(rest, "[") {
+		return nil, false
+	}
+	path, err := normalizeFieldPath(componentType, rest)
+	if err != nil {
+		return nil, false
+	}
+	return path, true
+}
+
+// normalizeFieldPath returns the segments of the field path, with the slice indices and map keys
+// parsed into the types of the component and formatted back, as in the responses of the server.
+// Below an interface, or if the type is nil, the segments are kept as written.
+func normalizeFieldPath(typ reflect.Type, fieldPath string) ([]string, error) {
+	segments, err := parseFieldPath(fieldPath)
+	if err != nil {
+		return nil, err
+	}
+
+	path := make([]string, len(segments))
+	for i, segment := range segments {
+		for typ != nil && typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		path[i] = segment.name
+		if typ == nil {
+			continue
+		}
+
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Array:
+			if index, err := strconv.Atoi(segment.name); err == nil {
+				path[i] = strconv.Itoa(index)
+			}
+			typ = typ.Elem()
+		case reflect.Map:
+			key := reflect.New(typ.Key()).Elem()
+			if err := decodeMapKey(key, segment.name); err == nil {
+				path[i] = formatMapKey(key)
+			}
+			typ = typ.Elem()
+		case reflect.Struct:
+			field, ok := typ.FieldByName(segment.name)
+			typ = nil
+			if ok {
+				typ = field.Type
+			}
+		default:
+			typ = nil
+		}
+	}
+	return path, nil
+}
+
+// coversPath reports whether the path is the prefix path, or a field held by it.
+func coversPath(prefix []string, path []string) bool {
+	return len(prefix) <= len(path) && slices.Equal(prefix, path[:len(prefix)])
+}
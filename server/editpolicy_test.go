@@ -0,0 +1,75 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditPolicy_Editable(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    editPolicy
+		component string
+		field     string
+		want      bool
+	}{
+		{"no restriction", editPolicy{}, "Player", "Health", true},
+		{"read-only", editPolicy{readOnly: true}, "Player", "Health", false},
+		{"denied component", editPolicy{deny: []string{"Player"}}, "Player", "Health", false},
+		{"denied field", editPolicy{deny: []string{"Player.Health"}}, "Player", "Health", false},
+		{"field held by denied field", editPolicy{deny: []string{"Object.Points"}}, "Object", "Points[0].X", false},
+		{"field holding denied field", editPolicy{deny: []string{"Player.Health"}}, "Player", "", false},
+		{"sibling of denied field", editPolicy{deny: []string{"Player.Health"}}, "Player", "HealthMax", true},
+		{"allowed field", editPolicy{allow: []string{"Player.Health"}}, "Player", "Health", true},
+		{"field held by allowed field", editPolicy{allow: []string{"Object.Points"}}, "Object", "Points[1]", true},
+		{"field not allowed", editPolicy{allow: []string{"Player.Health"}}, "Player", "Name", false},
+		{"allowed component", editPolicy{allow: []string{"Player"}}, "Player", "Name", true},
+		{"other component", editPolicy{allow: []string{"Player"}}, "PlayerData", "", false},
+		{"denied over allowed", editPolicy{allow: []string{"Player"}, deny: []string{"Player.Name"}}, "Player", "Name", false},
+		{"map entry", editPolicy{allow: []string{"Inventory[sword]"}}, "Inventory", "[sword]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.editable(tt.component, nil, tt.field))
+		})
+	}
+}
+
+func TestEditPolicy_EditableSpellings(t *testing.T) {
+	type Inventory struct {
+		Items  map[string]int
+		Scores map[int]int
+		Slots  []*Inventory
+	}
+	typ := reflect.TypeOf(Inventory{})
+	policy := editPolicy{deny: []string{"Inventory.Items[sword]", "Inventory.Scores[1]", "Inventory.Slots[0]"}}
+
+	tests := []struct {
+		field string
+		want  bool
+	}{
+		{"Items[sword]", false},
+		{"Items.sword", false},
+		{"Items[bow]", true},
+		{"Scores[1]", false},
+		{"Scores[01]", false},
+		{"Scores[+1]", false},
+		{"Scores.1", false},
+		{"Scores[10]", true},
+		{"Slots[00].Items", false},
+		{"Slots[1].Items", true},
+		{"Items[sword", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.editable("Inventory", typ, tt.field))
+		})
+	}
+
+	allowed := editPolicy{allow: []string{"Inventory.Scores[01]"}}
+	assert.True(t, allowed.editable("Inventory", typ, "Scores.1"))
+	assert.False(t, allowed.editable("Inventory", typ, "Scores[2]"))
+}
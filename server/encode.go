@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// encodeValue converts the value and everything it references into JSON-serializable values that
+// [decodeSnapshotValue] decodes back, e.g. for snapshots.
+//
+// Only exported struct fields are included, maps are converted to objects with keys formatted by [formatMapKey],
+// and fields that cannot be represented in JSON (funcs, channels, ...) are omitted. Values with an encoder in codecs,
+// which may be nil, are encoded by it, and values reached again through a pointer are replaced by references, see [RefPath].
+func encodeValue(value reflect.Value, codecs *Codecs) interface{} {
+	e := fieldEncoder{paths: make(map[refKey]string), codecs: codecs, decodable: true}
+	return e.encode(value, "", FullDepth)
+}
+
+// isEncodable returns false for the kinds that cannot be represented in JSON.
+func isEncodable(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	}
+	return true
+}
+
+// formatMapKey stringifies a map key, so that it can be parsed back by [decodeMapKey].
+// Keys implementing [encoding.TextMarshaler] are formatted with it, other basic keys are
+// formatted by kind, ignoring String methods.
+func formatMapKey(key reflect.Value) string {
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Bool:
+		return strconv.FormatBool(key.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(key.Float(), 'g', -1, key.Type().Bits())
+	default:
+		return fmt.Sprint(key.Interface())
+	}
+}
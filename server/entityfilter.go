@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/yohamta/donburi/component"
+)
+
+// EntityFilter narrows down the entities listed by GET /entities.
+// An entity is listed if it matches all the conditions.
+//
+// req: /entities?tag=Player&component=Object
+// req: /entities?archetype=Object,Player
+type EntityFilter struct {
+	// Tags are the names of tags the entity must have.
+	Tags []string
+	// Components are the names of data components the entity must have.
+	Components []string
+	// Archetype, if not empty, holds the names of all the entity's components and tags, in any order.
+	Archetype []string
+}
+
+// ParseEntityFilter reads the filter from query parameters.
+// Each parameter can be repeated or hold comma-separated names.
+func ParseEntityFilter(query url.Values) EntityFilter {
+	return EntityFilter{
+		Tags:       splitNames(query["tag"]),
+		Components: splitNames(query["component"]),
+		Archetype:  splitNames(query["archetype"]),
+	}
+}
+
+// Query encodes the filter as query parameters.
+func (f EntityFilter) Query() url.Values {
+	query := url.Values{}
+	for _, tag := range f.Tags {
+		query.Add("tag", tag)
+	}
+	for _, component := range f.Components {
+		query.Add("component", component)
+	}
+	if len(f.Archetype) > 0 {
+		query.Set("archetype", strings.Join(f.Archetype, ","))
+	}
+	return query
+}
+
+// IsEmpty reports whether the filter matches all entities.
+func (f EntityFilter) IsEmpty() bool {
+	return len(f.Tags) == 0 && len(f.Components) == 0 && len(f.Archetype) == 0
+}
+
+func (f EntityFilter) matches(componentTypes []component.IComponentType) bool {
+	var tags, components, all []string
+	for _, componentType := range componentTypes {
+		if isTag(componentType) {
+			tags = append(tags, componentType.Name())
+		} else {
+			components = append(components, componentType.Name())
+		}
+		all = append(all, componentType.Name())
+	}
+
+	for _, tag := range f.Tags {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	for _, component := range f.Components {
+		if !slices.Contains(components, component) {
+			return false
+		}
+	}
+	if len(f.Archetype) > 0 {
+		archetype := slices.Clone(f.Archetype)
+		slices.Sort(archetype)
+		slices.Sort(all)
+		if !slices.Equal(slices.Compact(archetype), slices.Compact(all)) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitNames(values []string) []string {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
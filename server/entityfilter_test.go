@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/component"
+)
+
+func TestParseEntityFilter(t *testing.T) {
+	query, err := url.ParseQuery("tag=Player&tag=Wall,Ramp&component=Object&archetype=Object, Player")
+	assert.NoError(t, err)
+
+	filter := ParseEntityFilter(query)
+	assert.Equal(t, EntityFilter{
+		Tags:       []string{"Player", "Wall", "Ramp"},
+		Components: []string{"Object"},
+		Archetype:  []string{"Object", "Player"},
+	}, filter)
+	assert.Equal(t, filter, ParseEntityFilter(filter.Query()))
+}
+
+func TestEntityFilter_Matches(t *testing.T) {
+	type Object struct {
+		X float64
+	}
+	object := donburi.NewComponentType[Object]()
+	object.SetName("Object")
+	player := donburi.NewTag("Player")
+	componentTypes := []component.IComponentType{object, player}
+
+	testCases := map[string]struct {
+		filter   EntityFilter
+		expected bool
+	}{
+		"empty":               {EntityFilter{}, true},
+		"tag":                 {EntityFilter{Tags: []string{"Player"}}, true},
+		"missing tag":         {EntityFilter{Tags: []string{"Wall"}}, false},
+		"component":           {EntityFilter{Components: []string{"Object"}}, true},
+		"tag as component":    {EntityFilter{Components: []string{"Player"}}, false},
+		"component as tag":    {EntityFilter{Tags: []string{"Object"}}, false},
+		"archetype":           {EntityFilter{Archetype: []string{"Player", "Object"}}, true},
+		"partial archetype":   {EntityFilter{Archetype: []string{"Object"}}, false},
+		"tag and component":   {EntityFilter{Tags: []string{"Player"}, Components: []string{"Object"}}, true},
+		"one condition fails": {EntityFilter{Tags: []string{"Player"}, Components: []string{"Tween"}}, false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.filter.matches(componentTypes))
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yohamta/donburi"
+)
+
+type EventType string
+
+const (
+	EventEntityCreated EventType = "entity_created"
+	EventEntityRemoved EventType = "entity_removed"
+	EventFieldChanged  EventType = "field_changed"
+)
+
+// Event is a change in the world streamed by the events endpoint.
+type Event struct {
+	Type      EventType     `json:"type"`
+	EntityId  string        `json:"entity_id"`
+	Component string        `json:"component,omitempty"`
+	Field     string        `json:"field,omitempty"`
+	Value     interface{}   `json:"value,omitempty"`
+	ValueType ComponentType `json:"value_type,omitempty"`
+}
+
+// watchInterval is how often the subscriptions compare the world against their last known state.
+//
+// The subscriptions of a world are polled together, in a single job on the game loop per interval, see [watchers].
+// Each round lists the entities once, and builds the index of the pointers between components at most once,
+// when a watched value holds pointers, which walks all components. The other work is proportional to the
+// number of subscriptions and the size of the values they watch.
+const watchInterval = 100 * time.Millisecond
+
+// req: /events?entity=3&component=PlayerData&field=IgnorePlatform
+// resp: text/event-stream of Event, e.g.
+//
+//	data: {"type": "field_changed", "entity_id": "3", "component": "PlayerData", "field": "IgnorePlatform", "value": true, "value_type": "primitive"}
+//
+// Entity created and removed events are always streamed.
+// If an entity is given, changes of all its components (or only the given component and field) are streamed too,
+// starting with their current values.
+func (s *Server) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	watcher := &watcher{
+		componentName: query.Get("component"),
+		fieldPath:     query.Get("field"),
+		codecs:        s.codecs,
+	}
+	if idStr := query.Get("entity"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid entity ID", http.StatusBadRequest)
+			return
+		}
+		watcher.entityID = uint32(id)
+		watcher.hasEntity = true
+	}
+
+	// The stream ends when the client disconnects, or when the world is removed.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	defer context.AfterFunc(s.ctx, cancel)()
+
+	var events []Event
+	err := s.execute(ctx, func() error {
+		var err error
+		events, err = watcher.poll(newPollRound(s.store))
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sub := s.watchers.subscribe(s, watcher)
+	defer s.watchers.unsubscribe(sub)
+
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's write timeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		for _, event := range events {
+			b, err := json.Marshal(event)
+			if err != nil {
+				panic(err)
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.ready:
+		}
+		events = sub.take()
+	}
+}
+
+// watchers polls the subscriptions to the events of a world every watchInterval, while there are any.
+type watchers struct {
+	mu      sync.Mutex
+	subs    map[*subscription]struct{}
+	running bool
+}
+
+// subscription holds the events of a watcher until they are streamed.
+type subscription struct {
+	watcher *watcher
+	mu      sync.Mutex
+	events  []Event
+	// ready is signaled when events are added.
+	ready chan struct{}
+}
+
+func newWatchers() *watchers {
+	return &watchers{subs: make(map[*subscription]struct{})}
+}
+
+// subscribe adds the watcher to the ones polled by the server, starting to poll if it is the first.
+func (ws *watchers) subscribe(s *Server, w *watcher) *subscription {
+	sub := &subscription{watcher: w, ready: make(chan struct{}, 1)}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.subs[sub] = struct{}{}
+	if !ws.running {
+		ws.running = true
+		go ws.poll(s)
+	}
+	return sub
+}
+
+func (ws *watchers) unsubscribe(sub *subscription) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	delete(ws.subs, sub)
+}
+
+// poll polls all the subscriptions every watchInterval, until there are none left or the server's context is cancelled.
+func (ws *watchers) poll(s *Server) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			ws.mu.Lock()
+			ws.running = false
+			ws.mu.Unlock()
+			return
+		case <-ticker.C:
+		}
+
+		ws.mu.Lock()
+		if len(ws.subs) == 0 {
+			ws.running = false
+			ws.mu.Unlock()
+			return
+		}
+		subs := make([]*subscription, 0, len(ws.subs))
+		for sub := range ws.subs {
+			subs = append(subs, sub)
+		}
+		ws.mu.Unlock()
+
+		// Errors are only returned by the first poll, and a round that times out is retried on the next tick.
+		_ = s.execute(s.ctx, func() error {
+			round := newPollRound(s.store)
+			for _, sub := range subs {
+				events, _ := sub.watcher.poll(round)
+				sub.add(events)
+			}
+			return nil
+		})
+	}
+}
+
+func (sub *subscription) add(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	sub.mu.Lock()
+	sub.events = append(sub.events, events...)
+	sub.mu.Unlock()
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (sub *subscription) take() []Event {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	events := sub.events
+	sub.events = nil
+	return events
+}
+
+// pollRound holds what the watchers polled together share: the entities, and the link index, built when first needed.
+type pollRound struct {
+	store   Store
+	entries map[uint32]*donburi.Entry
+	links   *lazyLinkIndex
+}
+
+func newPollRound(store Store) *pollRound {
+	return &pollRound{store: store, entries: store.GetEntries(), links: newLazyLinkIndex(store)}
+}
+
+// watcher diffs the world against the state it saw on the previous poll.
+type watcher struct {
+	entityID      uint32
+	hasEntity     bool
+	componentName string
+	fieldPath     string
+	codecs        *Codecs
+
+	entities map[uint32]struct{}
+	values   map[string]interface{}
+}
+
+// poll returns the events since the previous poll.
+// On the first poll, the watched values are reported as changed, and errors are returned for invalid subscriptions.
+func (w *watcher) poll(round *pollRound) ([]Event, error) {
+	first := w.entities == nil
+	var events []Event
+
+	entries := round.entries
+	entities := make(map[uint32]struct{}, len(entries))
+	for id := range entries {
+		entities[id] = struct{}{}
+		if _, ok := w.entities[id]; !first && !ok {
+			events = append(events, Event{
+				Type:     EventEntityCreated,
+				EntityId: strconv.FormatUint(uint64(id), 10),
+			})
+		}
+	}
+	for id := range w.entities {
+		if _, ok := entities[id]; !ok {
+			events = append(events, Event{
+				Type:     EventEntityRemoved,
+				EntityId: strconv.FormatUint(uint64(id), 10),
+			})
+		}
+	}
+	w.entities = entities
+
+	if !w.hasEntity {
+		return events, nil
+	}
+
+	entry := round.store.GetEntry(w.entityID)
+	if entry == nil {
+		if first {
+			return nil, errorWithStatus(http.StatusNotFound, "Entity not found")
+		}
+		return events, nil
+	}
+
+	if w.values == nil {
+		w.values = make(map[string]interface{})
+	}
+	found := false
+	for _, componentType := range entry.Archetype().ComponentTypes() {
+		name := componentType.Name()
+		if w.componentName != "" && name != w.componentName {
+			continue
+		}
+		found = true
+
+		component, _ := findComponent(entry, name)
+		response, err := getComponentResponse(component, w.fieldPath, DefaultDepth, round.links.owner(entry, name), w.codecs)
+		if err != nil {
+			if first {
+				return nil, err
+			}
+			continue
+		}
+
+		if previous, ok := w.values[name]; ok && reflect.DeepEqual(previous, response.Value) {
+			continue
+		}
+		w.values[name] = response.Value
+		events = append(events, Event{
+			Type:      EventFieldChanged,
+			EntityId:  strconv.FormatUint(uint64(w.entityID), 10),
+			Component: name,
+			Field:     w.fieldPath,
+			Value:     response.Value,
+			ValueType: response.Type,
+		})
+	}
+	if first && !found && w.componentName != "" {
+		return nil, errorWithStatus(http.StatusNotFound, "Component not found")
+	}
+
+	return events, nil
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+
+	"github.com/yohamta/donburi"
 )
 
 type ComponentType string
@@ -14,15 +16,44 @@ const (
 	ComponentTypeObject    ComponentType = "object"
 	ComponentTypeSlice     ComponentType = "slice"
 	ComponentTypeNil       ComponentType = "nil"
+	// ComponentTypeMap is an object whose keys are map keys, formatted as strings, rather than field names.
+	ComponentTypeMap ComponentType = "map"
 )
 
 type ComponentResponse struct {
 	Value interface{}   `json:"value"`
 	Type  ComponentType `json:"type"`
+	// Field describes the Go value at the requested path.
+	Field FieldInfo `json:"field"`
+	// Fields describe the struct fields, slice elements or map entries of the value,
+	// keyed by field name, index or formatted map key.
+	Fields map[string]FieldInfo `json:"fields,omitempty"`
+}
+
+// FieldInfo describes a Go value, so that clients can validate input before setting it.
+type FieldInfo struct {
+	// Type is the Go type, e.g. "float64" or "*image.Rectangle".
+	Type string `json:"type"`
+	// Kind is the [reflect.Kind] of the type, e.g. "float64" or "ptr".
+	Kind string `json:"kind"`
+	// Nullable is true if the value can be set to null, i.e. for pointers, interfaces, slices and maps.
+	Nullable bool `json:"nullable"`
+	// Settable is false for map entries, unexported fields and values reached through them.
+	Settable bool `json:"settable"`
+	Exported bool `json:"exported"`
+	// Tag is the struct tag of a struct field.
+	Tag string `json:"tag,omitempty"`
+	// Locked is true if the server does not allow the field to be edited, see [Config.EditAllow].
+	Locked bool `json:"locked,omitempty"`
 }
 
 // req: /entities/3/components/PlayerData?field=IgnorePlatform
 // resp: {"value": false, "type": "primitive"}
+//
+// The optional depth parameter sets how deep nested values are returned, see [parseDepth].
+//
+// The value is encoded on the game loop, in time proportional to its size. If it holds pointers,
+// every component of the world is walked once as well, to link the ones pointing into other components.
 func (s *Server) getComponentHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("entity_id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -30,31 +61,36 @@ func (s *Server) getComponentHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid entity ID", http.StatusBadRequest)
 		return
 	}
-	entry := s.store.GetEntry(uint32(id))
-	if entry == nil {
-		http.Error(w, "Entity not found", http.StatusNotFound)
-		return
-	}
 
 	fieldPath := r.URL.Query().Get("field")
 	componentName := r.PathValue("component_name")
-	var component reflect.Value
-	for _, componentType := range entry.Archetype().ComponentTypes() {
-		if componentType.Name() == componentName {
-			ptr := entry.Component(componentType)
-			component = reflect.Indirect(reflect.NewAt(componentType.Typ(), ptr))
-		}
+	depth, err := parseDepth(r)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
-	field, err := GetField(component, fieldPath)
+	var response ComponentResponse
+	err = s.execute(r.Context(), func() error {
+		entry := s.store.GetEntry(uint32(id))
+		if entry == nil {
+			return errorWithStatus(http.StatusNotFound, "Entity not found")
+		}
+
+		component, ok := findComponent(entry, componentName)
+		if !ok {
+			return errorWithStatus(http.StatusNotFound, "Component not found")
+		}
+
+		var err error
+		response, err = getComponentResponse(component, fieldPath, depth, newLazyLinkIndex(s.store).owner(entry, componentName), s.codecs)
+		s.policy.lockFields(componentName, component.Type(), fieldPath, &response)
+		return err
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
-	response := ComponentResponse{
-		Value: field,
-		Type:  reflectToComponentType(field),
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(response)
@@ -63,6 +99,31 @@ func (s *Server) getComponentHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// parseDepth returns the depth query parameter, [DefaultDepth] if it is not set.
+// A depth of -1 returns values in full, see [FullDepth].
+func parseDepth(r *http.Request) (int, error) {
+	depthStr := r.URL.Query().Get("depth")
+	if depthStr == "" {
+		return DefaultDepth, nil
+	}
+	depth, err := strconv.Atoi(depthStr)
+	if err != nil || depth < FullDepth {
+		return 0, errorWithStatus(http.StatusBadRequest, "Invalid depth")
+	}
+	return depth, nil
+}
+
+// findComponent returns an addressable value of the component with the given name.
+func findComponent(entry *donburi.Entry, componentName string) (reflect.Value, bool) {
+	for _, componentType := range entry.Archetype().ComponentTypes() {
+		if componentType.Name() == componentName {
+			ptr := entry.Component(componentType)
+			return reflect.Indirect(reflect.NewAt(componentType.Typ(), ptr)), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
 func reflectToComponentType(v interface{}) ComponentType {
 	if v == nil {
 		return ComponentTypeNil
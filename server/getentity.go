@@ -11,8 +11,10 @@ import (
 )
 
 type EntitySummary struct {
-	Id        string           `json:"id"`
-	Name      string           `json:"name"`
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// Tags are the names of the entity's components that carry no data, e.g. donburi tags.
+	Tags      []string         `json:"tags,omitempty"`
 	Archetype ArchetypeSummary `json:"archetype"`
 }
 
@@ -31,6 +33,11 @@ type GetEntityResponse struct {
 	Entity Entity `json:"entity"`
 }
 
+// req: /entities/3?depth=1
+// resp: {"entity": {"id": "3", "components": [{"name": "PlayerData", "value": {...}}]}}
+//
+// Like the component handler, the cost on the game loop grows with the size of the components,
+// plus a walk of the whole world if any of them holds pointers.
 func (s *Server) getEntityHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -38,17 +45,27 @@ func (s *Server) getEntityHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid entity ID", http.StatusBadRequest)
 		return
 	}
-	entry := s.store.GetEntry(uint32(id))
-	if entry == nil {
-		http.Error(w, "Entity not found", http.StatusNotFound)
+
+	depth, err := parseDepth(r)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	var summary EntitySummary = entitySummaryFromEntry(entry)
-	var entity Entity
-	entity.EntitySummary = summary
-	entity.Components = getComponentsFromEntry(entry)
-	response := GetEntityResponse{Entity: entity}
+	var response GetEntityResponse
+	err = s.execute(r.Context(), func() error {
+		entry := s.store.GetEntry(uint32(id))
+		if entry == nil {
+			return errorWithStatus(http.StatusNotFound, "Entity not found")
+		}
+
+		response = GetEntityResponse{Entity: entityFromEntryWithDepth(entry, depth, newLazyLinkIndex(s.store), s.codecs)}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(response)
@@ -57,30 +74,36 @@ func (s *Server) getEntityHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func entityFromEntry(entry *donburi.Entry, codecs *Codecs) Entity {
+	return entityFromEntryWithDepth(entry, DefaultDepth, nil, codecs)
+}
+
+// entityFromEntryWithDepth returns the entity with its component values up to depth, see [encodeField].
+// Pointers into other components are linked if links is not nil.
+func entityFromEntryWithDepth(entry *donburi.Entry, depth int, links *lazyLinkIndex, codecs *Codecs) Entity {
+	var summary EntitySummary = entitySummaryFromEntry(entry)
+	var entity Entity
+	entity.EntitySummary = summary
+	entity.Components = getComponentsFromEntry(entry, depth, links, codecs)
+	return entity
+}
+
 func entitySummaryFromEntry(entry *donburi.Entry) EntitySummary {
 	var entity EntitySummary
 	entity.Id = fmt.Sprintf("%d", entry.Id())
 	entity.Name = entry.String()
-	entity.Archetype.EntityCount = len(entry.Archetype().Entities())
-	for _, components := range entry.Archetype().ComponentTypes() {
-		entity.Archetype.Components = append(entity.Archetype.Components, struct {
-			Name string `json:"name"`
-			Type string `json:"type"`
-		}{
-			Name: components.Name(),
-			Type: components.Typ().Name(),
-		})
-	}
+	entity.Archetype = archetypeSummary(len(entry.Archetype().Entities()), entry.Archetype().ComponentTypes())
+	entity.Tags = entity.Archetype.Tags
 	return entity
 }
 
-func getComponentsFromEntry(entry *donburi.Entry) []Component {
+func getComponentsFromEntry(entry *donburi.Entry, depth int, links *lazyLinkIndex, codecs *Codecs) []Component {
 	var components []Component
 	componentTypes := entry.Archetype().ComponentTypes()
 	for _, componentType := range componentTypes {
 		ptr := entry.Component(componentType)
 		component := reflect.Indirect(reflect.NewAt(componentType.Typ(), ptr))
-		fields := recursivelyConstructValue(component, 1)
+		fields := encodeField(component, "", depth, links.owner(entry, componentType.Name()), codecs)
 
 		resp := Component{
 			Name:  componentType.Name(),
@@ -0,0 +1,355 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// DefaultHistorySize is the number of edits kept in the history if [Config.HistorySize] is not set.
+const DefaultHistorySize = 100
+
+// HistoryEntry is an edit of a component field made through the server.
+type HistoryEntry struct {
+	Id        int         `json:"id"`
+	EntityId  string      `json:"entity_id"`
+	Component string      `json:"component"`
+	Field     string      `json:"field"`
+	From      interface{} `json:"from"`
+	To        interface{} `json:"to"`
+	// Undone is true if the edit has been undone and can be redone.
+	Undone bool `json:"undone"`
+}
+
+type GetHistoryResponse struct {
+	// Entries are ordered from oldest to newest.
+	Entries []HistoryEntry `json:"entries"`
+}
+
+// edit holds deep copies of a field's value before and after it has been set, see [deepCopyValue].
+type edit struct {
+	id        int
+	entityId  uint32
+	component string
+	field     string
+	from, to  reflect.Value
+}
+
+// history is a bounded list of edits. Edits before the cursor are applied,
+// the ones after it have been undone.
+type history struct {
+	mu     sync.Mutex
+	edits  []*edit
+	cursor int
+	size   int
+	nextId int
+}
+
+func newHistory(size int) *history {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &history{size: size, nextId: 1}
+}
+
+// record adds an edit, discarding the edits that have been undone.
+func (h *history) record(entityId uint32, component string, field string, from, to reflect.Value) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.edits = append(h.edits[:h.cursor], &edit{
+		id:        h.nextId,
+		entityId:  entityId,
+		component: component,
+		field:     field,
+		from:      from,
+		to:        to,
+	})
+	h.nextId++
+	if len(h.edits) > h.size {
+		h.edits = h.edits[len(h.edits)-h.size:]
+	}
+	h.cursor = len(h.edits)
+}
+
+// undo applies the previous value of the last applied edit.
+func (h *history) undo(store Store) (*edit, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cursor == 0 {
+		return nil, errorWithStatus(http.StatusConflict, "Nothing to undo")
+	}
+	e := h.edits[h.cursor-1]
+	if err := e.apply(store, e.from); err != nil {
+		return nil, err
+	}
+	h.cursor--
+	return e, nil
+}
+
+// redo applies the new value of the first undone edit.
+func (h *history) redo(store Store) (*edit, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cursor == len(h.edits) {
+		return nil, errorWithStatus(http.StatusConflict, "Nothing to redo")
+	}
+	e := h.edits[h.cursor]
+	if err := e.apply(store, e.to); err != nil {
+		return nil, err
+	}
+	h.cursor++
+	return e, nil
+}
+
+func (h *history) entries(codecs *Codecs) []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := make([]HistoryEntry, len(h.edits))
+	for i, e := range h.edits {
+		entries[i] = e.entry(i >= h.cursor, codecs)
+	}
+	return entries
+}
+
+func (e *edit) apply(store Store, value reflect.Value) error {
+	entry := store.GetEntry(e.entityId)
+	if entry == nil || !entry.Valid() {
+		return errorWithStatus(http.StatusConflict, "Entity of the edit no longer exists")
+	}
+	component, ok := findComponent(entry, e.component)
+	if !ok {
+		return errorWithStatus(http.StatusConflict, "Component of the edit no longer exists")
+	}
+	field, err := findField(component, e.field)
+	if err != nil {
+		return errorWithStatus(http.StatusConflict, "Field of the edit no longer exists: "+err.Error())
+	}
+	if !field.CanSet() || field.Type() != value.Type() {
+		return errorWithStatus(http.StatusConflict, "Field of the edit cannot be set")
+	}
+	assignValue(field, value)
+	return nil
+}
+
+// assignValue sets the field to a copy of the value, so that the edit keeps its own copy when the field
+// is changed later. Like [decodeValue], structs are set field by field and values pointed to in place,
+// so other references to them see the change.
+func assignValue(field reflect.Value, value reflect.Value) {
+	assign(field, value, make(map[refKey]bool), make(map[refKey]reflect.Value))
+}
+
+// assign sets the field to a copy of the value. assigned holds the pointers whose values have been set, to stop at cycles.
+func assign(field reflect.Value, value reflect.Value, assigned map[refKey]bool, copies map[refKey]reflect.Value) {
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.IsNil() || value.IsNil() || !field.Elem().CanSet() {
+			break
+		}
+		key := refKey{addr: field.Pointer(), typ: field.Type()}
+		if !assigned[key] {
+			assigned[key] = true
+			assign(field.Elem(), value.Elem(), assigned, copies)
+		}
+		return
+
+	case reflect.Struct:
+		// Structs with unexported fields, e.g. time.Time, are set as a whole.
+		if !hasUnexportedField(field.Type()) {
+			for i := 0; i < field.NumField(); i++ {
+				assign(field.Field(i), value.Field(i), assigned, copies)
+			}
+			return
+		}
+
+	case reflect.Array:
+		for i := 0; i < field.Len(); i++ {
+			assign(field.Index(i), value.Index(i), assigned, copies)
+		}
+		return
+	}
+
+	c := reflect.New(value.Type()).Elem()
+	deepCopy(c, value, copies)
+	field.Set(c)
+}
+
+func (e *edit) entry(undone bool, codecs *Codecs) HistoryEntry {
+	return HistoryEntry{
+		Id:        e.id,
+		EntityId:  strconv.FormatUint(uint64(e.entityId), 10),
+		Component: e.component,
+		Field:     e.field,
+		From:      encodeValue(e.from, codecs),
+		To:        encodeValue(e.to, codecs),
+		Undone:    undone,
+	}
+}
+
+func hasUnexportedField(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		if !typ.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// copyValue returns a shallow copy of the value, which is not affected by later changes to the original.
+func copyValue(value reflect.Value) reflect.Value {
+	c := reflect.New(value.Type()).Elem()
+	c.Set(value)
+	return c
+}
+
+// deepCopyValue returns a copy of the value that does not share the values it points to, the elements of its slices
+// or the entries of its maps with the original, so that it is not affected by changes made through them.
+// Values shared by several pointers, including cycles, stay shared in the copy. Unexported fields are copied shallowly.
+func deepCopyValue(value reflect.Value) reflect.Value {
+	c := reflect.New(value.Type()).Elem()
+	deepCopy(c, value, make(map[refKey]reflect.Value))
+	return c
+}
+
+// deepCopy copies the value into the target, which is settable. copies maps the pointers copied so far to their copies.
+func deepCopy(target reflect.Value, value reflect.Value, copies map[refKey]reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			target.Set(value)
+			return
+		}
+		key := refKey{addr: value.Pointer(), typ: value.Type()}
+		if c, ok := copies[key]; ok {
+			target.Set(c)
+			return
+		}
+		c := reflect.New(value.Type().Elem())
+		copies[key] = c
+		deepCopy(c.Elem(), value.Elem(), copies)
+		target.Set(c)
+
+	case reflect.Struct:
+		target.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if target.Field(i).CanSet() {
+				deepCopy(target.Field(i), value.Field(i), copies)
+			}
+		}
+
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			deepCopy(target.Index(i), value.Index(i), copies)
+		}
+
+	case reflect.Slice:
+		if value.IsNil() {
+			target.Set(value)
+			return
+		}
+		c := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			deepCopy(c.Index(i), value.Index(i), copies)
+		}
+		target.Set(c)
+
+	case reflect.Map:
+		if value.IsNil() {
+			target.Set(value)
+			return
+		}
+		c := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			elem := reflect.New(value.Type().Elem()).Elem()
+			deepCopy(elem, iter.Value(), copies)
+			c.SetMapIndex(iter.Key(), elem)
+		}
+		target.Set(c)
+
+	case reflect.Interface:
+		if value.IsNil() {
+			target.Set(value)
+			return
+		}
+		elem := reflect.New(value.Elem().Type()).Elem()
+		deepCopy(elem, value.Elem(), copies)
+		target.Set(elem)
+
+	default:
+		target.Set(value)
+	}
+}
+
+// req: GET /history
+// resp: {"entries": [{"id": 1, "entity_id": "3", "component": "PlayerData", "field": "Speed", "from": 1, "to": 2, "undone": false}]}
+func (s *Server) getHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	var response GetHistoryResponse
+	err := s.execute(r.Context(), func() error {
+		response.Entries = s.history.entries(s.codecs)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// req: POST /history/undo
+// resp: {"id": 1, "entity_id": "3", "component": "PlayerData", "field": "Speed", "from": 1, "to": 2, "undone": true}
+func (s *Server) undoHandler(w http.ResponseWriter, r *http.Request) {
+	s.applyHistory(w, r, func() (HistoryEntry, error) {
+		e, err := s.history.undo(s.store)
+		if err != nil {
+			return HistoryEntry{}, err
+		}
+		return e.entry(true, s.codecs), nil
+	})
+}
+
+// req: POST /history/redo
+// resp: {"id": 1, "entity_id": "3", "component": "PlayerData", "field": "Speed", "from": 1, "to": 2, "undone": false}
+func (s *Server) redoHandler(w http.ResponseWriter, r *http.Request) {
+	s.applyHistory(w, r, func() (HistoryEntry, error) {
+		e, err := s.history.redo(s.store)
+		if err != nil {
+			return HistoryEntry{}, err
+		}
+		return e.entry(false, s.codecs), nil
+	})
+}
+
+func (s *Server) applyHistory(w http.ResponseWriter, r *http.Request, fn func() (HistoryEntry, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var response HistoryEntry
+	err := s.execute(r.Context(), func() error {
+		var err error
+		response, err = fn()
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_Record(t *testing.T) {
+	h := newHistory(2)
+	h.record(1, "Object", "X", reflect.ValueOf(1), reflect.ValueOf(2))
+	h.record(1, "Object", "X", reflect.ValueOf(2), reflect.ValueOf(3))
+	h.record(1, "Object", "X", reflect.ValueOf(3), reflect.ValueOf(4))
+
+	entries := h.entries(nil)
+	require.Len(t, entries, 2, "oldest edit should be dropped")
+	assert.Equal(t, HistoryEntry{Id: 2, EntityId: "1", Component: "Object", Field: "X", From: 2, To: 3}, entries[0])
+	assert.Equal(t, HistoryEntry{Id: 3, EntityId: "1", Component: "Object", Field: "X", From: 3, To: 4}, entries[1])
+}
+
+func TestHistory_RecordDiscardsUndone(t *testing.T) {
+	h := newHistory(0)
+	h.record(1, "Object", "X", reflect.ValueOf(1), reflect.ValueOf(2))
+	h.record(1, "Object", "X", reflect.ValueOf(2), reflect.ValueOf(3))
+	h.cursor = 1 // as if the last edit has been undone
+	assert.True(t, h.entries(nil)[1].Undone)
+
+	h.record(1, "Object", "Y", reflect.ValueOf(1), reflect.ValueOf(5))
+
+	entries := h.entries(nil)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Y", entries[1].Field)
+	assert.False(t, entries[1].Undone)
+}
+
+func TestHistory_NothingToUndo(t *testing.T) {
+	h := newHistory(0)
+
+	_, err := h.undo(nil)
+	var statusErr *statusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusConflict, statusErr.code)
+
+	_, err = h.redo(nil)
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusConflict, statusErr.code)
+}
+
+func TestDeepCopyValue(t *testing.T) {
+	type node struct {
+		Name  string
+		Next  *node
+		Items []int
+		Tags  map[string]int
+	}
+	a := &node{Name: "a", Items: []int{1}, Tags: map[string]int{"x": 1}}
+	a.Next = a
+
+	c := deepCopyValue(reflect.ValueOf(a)).Interface().(*node)
+	a.Name = "b"
+	a.Items[0] = 2
+	a.Tags["x"] = 2
+
+	assert.NotSame(t, a, c)
+	assert.Same(t, c, c.Next, "cycles should be kept")
+	assert.Equal(t, "a", c.Name)
+	assert.Equal(t, []int{1}, c.Items)
+	assert.Equal(t, map[string]int{"x": 1}, c.Tags)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yohamta/donburi"
+)
+
+// LinkKey is the key of the objects that stand in for values owned by another component, see [LinkOf].
+const LinkKey = "$link"
+
+// Link locates a value owned by a component of an entity.
+type Link struct {
+	EntityId  string `json:"entity"`
+	Component string `json:"component"`
+	Path      string `json:"path"`
+}
+
+// LinkOf returns the location of the value referenced by an encoded value, if it is a link.
+// Pointers into the memory of another component, possibly of another entity, are not encoded
+// with the component. They are replaced by {"$link": {"entity": "<id>", "component": "<name>", "path": "<path>"}}.
+func LinkOf(value interface{}) (Link, bool) {
+	obj, ok := value.(map[string]interface{})
+	if !ok || len(obj) != 1 {
+		return Link{}, false
+	}
+	fields, ok := obj[LinkKey].(map[string]interface{})
+	if !ok {
+		return Link{}, false
+	}
+	entityId, _ := fields["entity"].(string)
+	component, _ := fields["component"].(string)
+	path, _ := fields["path"].(string)
+	return Link{EntityId: entityId, Component: component, Path: path}, true
+}
+
+// String formats the link as "entity <id> <component>.<path>".
+func (l Link) String() string {
+	location := l.Component
+	if l.Path != "" && !strings.HasPrefix(l.Path, "[") {
+		location += "."
+	}
+	return "entity " + l.EntityId + " " + location + l.Path
+}
+
+func (l Link) encode() map[string]interface{} {
+	return map[string]interface{}{
+		LinkKey: map[string]interface{}{
+			"entity":    l.EntityId,
+			"component": l.Component,
+			"path":      l.Path,
+		},
+	}
+}
+
+// linkIndex maps the memory owned by components to their location. A component owns its value and the
+// fields stored inline in it; if the component is a pointer, it owns the value it points to instead.
+type linkIndex map[refKey]Link
+
+func newLinkIndex(store Store) linkIndex {
+	entries := store.GetEntries()
+	ids := make([]uint32, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	// Values pointed to by several pointer components are owned by the entity with the lowest ID.
+	slices.Sort(ids)
+
+	index := make(linkIndex)
+	for _, id := range ids {
+		entry := entries[id]
+		if !entry.Valid() {
+			continue
+		}
+		for _, componentType := range entry.Archetype().ComponentTypes() {
+			component, _ := findComponent(entry, componentType.Name())
+			if component.Kind() == reflect.Ptr {
+				component = component.Elem()
+			}
+			index.add(component, Link{
+				EntityId:  strconv.FormatUint(uint64(id), 10),
+				Component: componentType.Name(),
+			})
+		}
+	}
+	return index
+}
+
+func (index linkIndex) add(value reflect.Value, link Link) {
+	if !value.IsValid() || !value.CanAddr() || value.Type().Size() == 0 {
+		return
+	}
+	key := refKey{addr: value.UnsafeAddr(), typ: value.Type()}
+	if _, ok := index[key]; !ok {
+		index[key] = link
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			name := value.Type().Field(i).Name
+			index.add(value.Field(i), Link{EntityId: link.EntityId, Component: link.Component, Path: JoinFieldPath(link.Path, name)})
+		}
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			index.add(value.Index(i), Link{EntityId: link.EntityId, Component: link.Component, Path: fmt.Sprintf("%s[%d]", link.Path, i)})
+		}
+	}
+}
+
+// lazyLinkIndex is the link index of a store, built when a pointer is first looked up.
+// Building the index walks every component of every entity, so values without pointers are encoded without it.
+type lazyLinkIndex struct {
+	store Store
+	index linkIndex
+}
+
+func newLazyLinkIndex(store Store) *lazyLinkIndex {
+	return &lazyLinkIndex{store: store}
+}
+
+func (l *lazyLinkIndex) get() linkIndex {
+	if l.index == nil {
+		l.index = newLinkIndex(l.store)
+	}
+	return l.index
+}
+
+// owner returns the links of the component of the entry, or nil if l is nil.
+func (l *lazyLinkIndex) owner(entry *donburi.Entry, componentName string) *componentLinks {
+	if l == nil {
+		return nil
+	}
+	return &componentLinks{
+		index: l,
+		owner: Link{EntityId: strconv.FormatUint(uint64(entry.Id()), 10), Component: componentName},
+	}
+}
+
+// componentLinks links the pointers of a component that point into other components.
+type componentLinks struct {
+	index *lazyLinkIndex
+	owner Link
+}
+
+// lookup returns the location of the value the pointer points to, if another component owns it.
+func (l *componentLinks) lookup(ptr reflect.Value) (Link, bool) {
+	if l == nil || ptr.Kind() != reflect.Ptr {
+		return Link{}, false
+	}
+	key, ok := refKeyOf(ptr)
+	if !ok {
+		return Link{}, false
+	}
+	link, ok := l.index.get()[key]
+	if !ok || (link.EntityId == l.owner.EntityId && link.Component == l.owner.Component) {
+		return Link{}, false
+	}
+	return link, true
+}
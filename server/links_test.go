@@ -0,0 +1,113 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/ecs"
+
+	"github.com/thefishhat/tamago/store"
+)
+
+func TestEncodeField_Links(t *testing.T) {
+	type Object struct {
+		X, Y float64
+	}
+	type Shape struct {
+		Points [2]Object
+	}
+	type PlayerData struct {
+		OnGround *Object
+		Corner   *Object
+		Self     *Object
+		Own      Object
+	}
+	objectComponent := donburi.NewComponentType[*Object]()
+	objectComponent.SetName("Object")
+	shapeComponent := donburi.NewComponentType[Shape]()
+	shapeComponent.SetName("Shape")
+	playerComponent := donburi.NewComponentType[PlayerData]()
+	playerComponent.SetName("PlayerData")
+
+	world := donburi.NewWorld()
+	s := store.NewStore(ecs.NewECS(world))
+	platform := world.Entry(world.Create(objectComponent, shapeComponent))
+	player := world.Entry(world.Create(playerComponent))
+	s.AddEntry(platform)
+	s.AddEntry(player)
+
+	objectComponent.SetValue(platform, &Object{X: 1})
+	data := playerComponent.Get(player)
+	data.OnGround = *objectComponent.Get(platform)
+	data.Corner = &shapeComponent.Get(platform).Points[1]
+	data.Self = &data.Own
+
+	component, ok := findComponent(player, "PlayerData")
+	require.True(t, ok)
+	response, err := getComponentResponse(component, "", DefaultDepth, newLazyLinkIndex(s).owner(player, "PlayerData"), nil)
+	require.NoError(t, err)
+
+	platformId := strconv.FormatUint(uint64(platform.Id()), 10)
+	fields := response.Value.(map[string]interface{})
+	link, ok := LinkOf(fields["OnGround"])
+	assert.True(t, ok)
+	assert.Equal(t, Link{EntityId: platformId, Component: "Object"}, link)
+	link, ok = LinkOf(fields["Corner"])
+	assert.True(t, ok)
+	assert.Equal(t, Link{EntityId: platformId, Component: "Shape", Path: "Points[1]"}, link)
+	_, ok = LinkOf(fields["Self"])
+	assert.False(t, ok, "pointers into the encoded component are not links")
+
+	// Without links, pointers are encoded with the component.
+	response, err = getComponentResponse(component, "OnGround", FullDepth, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"X": 1.0, "Y": 0.0}, response.Value)
+
+	// The linked component itself is not linked to.
+	component, ok = findComponent(platform, "Object")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"X": 1.0, "Y": 0.0}, encodeField(component, "", FullDepth, newLazyLinkIndex(s).owner(platform, "Object"), nil))
+
+	assert.Equal(t, "entity "+platformId+" Shape.Points[1]", link.String())
+	_, ok = LinkOf(map[string]interface{}{RefKey: "X"})
+	assert.False(t, ok)
+}
+
+func TestLazyLinkIndex(t *testing.T) {
+	type Object struct {
+		X, Y float64
+	}
+	type PlayerData struct {
+		Name     string
+		OnGround *Object
+	}
+	objectComponent := donburi.NewComponentType[Object]()
+	objectComponent.SetName("Object")
+	playerComponent := donburi.NewComponentType[PlayerData]()
+	playerComponent.SetName("PlayerData")
+
+	world := donburi.NewWorld()
+	s := store.NewStore(ecs.NewECS(world))
+	platform := world.Entry(world.Create(objectComponent))
+	player := world.Entry(world.Create(playerComponent))
+	s.AddEntry(platform)
+	s.AddEntry(player)
+
+	// Values without pointers do not need the index, nor do nil pointers.
+	links := newLazyLinkIndex(s)
+	component, _ := findComponent(platform, "Object")
+	encodeField(component, "", FullDepth, links.owner(platform, "Object"), nil)
+	component, _ = findComponent(player, "PlayerData")
+	encodeField(component, "", FullDepth, links.owner(player, "PlayerData"), nil)
+	assert.Nil(t, links.index, "the index should not be built")
+
+	playerComponent.Get(player).OnGround = objectComponent.Get(platform)
+	encoded := encodeField(component, "", FullDepth, links.owner(player, "PlayerData"), nil).(map[string]interface{})
+	assert.NotNil(t, links.index, "the index should be built for the pointer")
+	link, ok := LinkOf(encoded["OnGround"])
+	assert.True(t, ok)
+	assert.Equal(t, Link{EntityId: strconv.FormatUint(uint64(platform.Id()), 10), Component: "Object"}, link)
+}
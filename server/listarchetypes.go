@@ -3,6 +3,10 @@ package server
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/component"
 )
 
 type ComponentSummary struct {
@@ -13,32 +17,55 @@ type ComponentSummary struct {
 type ArchetypeSummary struct {
 	EntityCount int                `json:"entity_count"`
 	Components  []ComponentSummary `json:"components"`
+	// Tags are the names of the archetype's components that carry no data.
+	Tags []string `json:"tags,omitempty"`
 }
 
 type ListArchetypesResponse struct {
 	Archetypes []ArchetypeSummary `json:"archetypes"`
 }
 
-func (s *Server) listArchetypesHandler(w http.ResponseWriter, _ *http.Request) {
+func (s *Server) listArchetypesHandler(w http.ResponseWriter, r *http.Request) {
 	var response ListArchetypesResponse
-	for _, arch := range s.store.GetWorld().Archetypes() {
-		entities := arch.Entities()
-		if len(entities) == 0 {
-			continue
-		}
-		var archetype ArchetypeSummary
-		archetype.EntityCount = len(entities)
-		for _, components := range arch.ComponentTypes() {
-			archetype.Components = append(archetype.Components, struct {
-				Name string `json:"name"`
-				Type string `json:"type"`
-			}{
-				Name: components.Name(),
-				Type: components.Typ().Name(),
-			})
+	err := s.execute(r.Context(), func() error {
+		for _, arch := range s.store.GetWorld().Archetypes() {
+			entities := arch.Entities()
+			if len(entities) == 0 {
+				continue
+			}
+			archetype := archetypeSummary(len(entities), arch.ComponentTypes())
+			response.Archetypes = append(response.Archetypes, archetype)
 		}
-		response.Archetypes = append(response.Archetypes, archetype)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
 	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+func archetypeSummary(entityCount int, componentTypes []component.IComponentType) ArchetypeSummary {
+	var archetype ArchetypeSummary
+	archetype.EntityCount = entityCount
+	for _, components := range componentTypes {
+		if isTag(components) {
+			archetype.Tags = append(archetype.Tags, components.Name())
+			continue
+		}
+		archetype.Components = append(archetype.Components, ComponentSummary{
+			Name: components.Name(),
+			Type: components.Typ().Name(),
+		})
+	}
+	return archetype
+}
+
+var tagType = reflect.TypeOf(donburi.Tag(""))
+
+// isTag reports whether the component type carries no data, such as the ones created with [donburi.NewTag].
+func isTag(componentType component.IComponentType) bool {
+	return componentType.Typ() == tagType || componentType.Typ().Size() == 0
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// unixScheme prefixes the addresses of unix domain sockets, e.g. "unix:///run/user/1000/tamago.sock".
+const unixScheme = "unix://"
+
+// SocketPath returns the path of the socket of a unix address, e.g. "/tmp/tamago.sock" for "unix:///tmp/tamago.sock".
+// It returns false for TCP addresses.
+func SocketPath(addr string) (string, bool) {
+	return strings.CutPrefix(addr, unixScheme)
+}
+
+// listen listens on a TCP address, or on a unix socket if the address starts with "unix://".
+// Without a path, the socket is created in the runtime directory and named after the process,
+// so that several games can run side by side.
+func listen(addr string) (net.Listener, error) {
+	path, ok := SocketPath(addr)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	if path == "" {
+		path = filepath.Join(runtimeDir(), "tamago", fmt.Sprintf("%d.sock", os.Getpid()))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	removeStaleSocket(path)
+	return net.Listen("unix", path)
+}
+
+// removeStaleSocket removes the socket left behind by a process that did not shut down its server,
+// unless a server still accepts connections on it.
+func removeStaleSocket(path string) {
+	if info, err := os.Stat(path); err != nil || info.Mode().Type() != os.ModeSocket {
+		return
+	}
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return
+	}
+	os.Remove(path)
+}
+
+func runtimeDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return dir
+	}
+	return os.TempDir()
+}
@@ -2,18 +2,36 @@ package server
 
 import (
 	"encoding/json"
+	"maps"
 	"net/http"
+	"slices"
 )
 
 type ListEntitiesResponse struct {
 	Entities []EntitySummary `json:"entities"`
 }
 
-func (s *Server) listEntitiesHandler(w http.ResponseWriter, _ *http.Request) {
+// req: /entities?tag=Player&component=Object, see [EntityFilter]
+// resp: {"entities": [...]}
+func (s *Server) listEntitiesHandler(w http.ResponseWriter, r *http.Request) {
+	filter := ParseEntityFilter(r.URL.Query())
+
 	var response ListEntitiesResponse
-	for _, entry := range s.store.GetEntries() {
-		var entity EntitySummary = entitySummaryFromEntry(entry)
-		response.Entities = append(response.Entities, entity)
+	err := s.execute(r.Context(), func() error {
+		entries := s.store.GetEntries()
+		for _, id := range slices.Sorted(maps.Keys(entries)) {
+			entry := entries[id]
+			if !filter.matches(entry.Archetype().ComponentTypes()) {
+				continue
+			}
+			var entity EntitySummary = entitySummaryFromEntry(entry)
+			response.Entities = append(response.Entities, entity)
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
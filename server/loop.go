@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// LoopController pauses, resumes and steps the game loop, see [loop.Controller].
+type LoopController interface {
+	Pause()
+	Resume()
+	Step(frames int)
+	Paused() bool
+	Frame() uint64
+}
+
+type LoopStatus struct {
+	Paused bool   `json:"paused"`
+	Frame  uint64 `json:"frame"`
+}
+
+// req: GET /loop
+// resp: {"paused": true, "frame": 1234}
+func (s *Server) getLoopHandler(w http.ResponseWriter, r *http.Request) {
+	s.loopHandler(w, r, http.MethodGet, func() error { return nil })
+}
+
+// req: POST /loop/pause
+// resp: {"paused": true, "frame": 1234}
+func (s *Server) pauseLoopHandler(w http.ResponseWriter, r *http.Request) {
+	s.loopHandler(w, r, http.MethodPost, func() error {
+		s.loop.Pause()
+		return nil
+	})
+}
+
+// req: POST /loop/resume
+// resp: {"paused": false, "frame": 1234}
+func (s *Server) resumeLoopHandler(w http.ResponseWriter, r *http.Request) {
+	s.loopHandler(w, r, http.MethodPost, func() error {
+		s.loop.Resume()
+		return nil
+	})
+}
+
+// req: POST /loop/step?frames=10
+// resp: {"paused": true, "frame": 1234}
+//
+// The loop is paused, and resumes for the given number of frames (1 by default).
+// The response is sent before the frames have been simulated.
+func (s *Server) stepLoopHandler(w http.ResponseWriter, r *http.Request) {
+	s.loopHandler(w, r, http.MethodPost, func() error {
+		frames := 1
+		if framesStr := r.URL.Query().Get("frames"); framesStr != "" {
+			var err error
+			frames, err = strconv.Atoi(framesStr)
+			if err != nil || frames < 1 {
+				return errorWithStatus(http.StatusBadRequest, "Invalid number of frames")
+			}
+		}
+		s.loop.Step(frames)
+		return nil
+	})
+}
+
+// loopHandler applies fn to the loop and responds with the resulting status.
+func (s *Server) loopHandler(w http.ResponseWriter, r *http.Request, method string, fn func() error) {
+	if s.loop == nil {
+		http.Error(w, "Loop control is not available", http.StatusNotImplemented)
+		return
+	}
+	if r.Method != method {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := fn(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(LoopStatus{
+		Paused: s.loop.Paused(),
+		Frame:  s.loop.Frame(),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
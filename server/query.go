@@ -0,0 +1,53 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/yohamta/donburi"
+)
+
+// req: /query?filter={"and": [{"contains": ["Object", "Tween"]}, {"not": {"contains": ["Player"]}}]}
+// resp: {"entities": [...]}
+//
+// The filter is a JSON encoded [QueryFilter].
+func (s *Server) queryHandler(w http.ResponseWriter, r *http.Request) {
+	var queryFilter QueryFilter
+	if err := json.Unmarshal([]byte(r.URL.Query().Get("filter")), &queryFilter); err != nil {
+		http.Error(w, "Invalid filter", http.StatusBadRequest)
+		return
+	}
+
+	var response ListEntitiesResponse
+	err := s.execute(r.Context(), func() error {
+		world := s.store.GetWorld()
+		layoutFilter, err := queryFilter.layoutFilter(newComponentRegistry(world))
+		if err != nil {
+			return err
+		}
+
+		var entries []*donburi.Entry
+		donburi.NewQuery(layoutFilter).Each(world, func(entry *donburi.Entry) {
+			entries = append(entries, entry)
+		})
+		slices.SortFunc(entries, func(a, b *donburi.Entry) int {
+			return cmp.Compare(a.Id(), b.Id())
+		})
+		for _, entry := range entries {
+			response.Entities = append(response.Entities, entitySummaryFromEntry(entry))
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
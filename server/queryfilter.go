@@ -0,0 +1,229 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/yohamta/donburi/filter"
+)
+
+// QueryFilter is a filter expression over component and tag names, mirroring donburi's filter package.
+// Exactly one of its fields must be set.
+//
+// Example, all entities with Object and Tween but not Player:
+//
+//	{"and": [{"contains": ["Object", "Tween"]}, {"not": {"contains": ["Player"]}}]}
+type QueryFilter struct {
+	// Contains matches entities having all the given components, see [filter.Contains].
+	Contains []string `json:"contains,omitempty"`
+	// Exact matches entities having exactly the given components, see [filter.Exact].
+	Exact []string `json:"exact,omitempty"`
+	// And matches entities matching all the given filters, see [filter.And].
+	And []QueryFilter `json:"and,omitempty"`
+	// Or matches entities matching any of the given filters, see [filter.Or].
+	Or []QueryFilter `json:"or,omitempty"`
+	// Not matches entities not matching the given filter, see [filter.Not].
+	Not *QueryFilter `json:"not,omitempty"`
+}
+
+// layoutFilter resolves the component names and builds the equivalent donburi filter.
+func (f QueryFilter) layoutFilter(registry *componentRegistry) (filter.LayoutFilter, error) {
+	set := 0
+	for _, isSet := range []bool{f.Contains != nil, f.Exact != nil, f.And != nil, f.Or != nil, f.Not != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.New("filter must have exactly one of contains, exact, and, or, not")
+	}
+
+	switch {
+	case f.Contains != nil:
+		componentTypes, err := registry.lookupAll(f.Contains)
+		if err != nil {
+			return nil, err
+		}
+		return filter.Contains(componentTypes...), nil
+	case f.Exact != nil:
+		componentTypes, err := registry.lookupAll(f.Exact)
+		if err != nil {
+			return nil, err
+		}
+		return filter.Exact(componentTypes), nil
+	case f.Not != nil:
+		inner, err := f.Not.layoutFilter(registry)
+		if err != nil {
+			return nil, err
+		}
+		return filter.Not(inner), nil
+	}
+
+	operands := f.And
+	if f.Or != nil {
+		operands = f.Or
+	}
+	filters := make([]filter.LayoutFilter, 0, len(operands))
+	for _, operand := range operands {
+		inner, err := operand.layoutFilter(registry)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, inner)
+	}
+	if f.Or != nil {
+		return filter.Or(filters...), nil
+	}
+	return filter.And(filters...), nil
+}
+
+// String formats the filter in the syntax understood by [ParseQueryFilter].
+func (f QueryFilter) String() string {
+	switch {
+	case f.Contains != nil:
+		return "contains(" + strings.Join(f.Contains, ", ") + ")"
+	case f.Exact != nil:
+		return "exact(" + strings.Join(f.Exact, ", ") + ")"
+	case f.Not != nil:
+		return "not(" + f.Not.String() + ")"
+	case f.And != nil:
+		return "and(" + joinFilters(f.And) + ")"
+	case f.Or != nil:
+		return "or(" + joinFilters(f.Or) + ")"
+	default:
+		return ""
+	}
+}
+
+func joinFilters(filters []QueryFilter) string {
+	formatted := make([]string, 0, len(filters))
+	for _, f := range filters {
+		formatted = append(formatted, f.String())
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// ParseQueryFilter parses a filter expression built from the functions
+// contains, exact, and, or and not, e.g.
+//
+//	and(contains(Object, Tween), not(contains(Player)))
+//
+// A bare name is short for contains(name), so the above can also be written as
+//
+//	and(Object, Tween, not(Player))
+func ParseQueryFilter(expr string) (QueryFilter, error) {
+	p := &queryParser{input: expr}
+	f, err := p.parseExpr()
+	if err != nil {
+		return QueryFilter{}, err
+	}
+	p.skipSpaces()
+	if p.pos != len(p.input) {
+		return QueryFilter{}, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:], p.pos)
+	}
+	return f, nil
+}
+
+type queryParser struct {
+	input string
+	pos   int
+}
+
+func (p *queryParser) parseExpr() (QueryFilter, error) {
+	name := p.parseName()
+	if name == "" {
+		return QueryFilter{}, fmt.Errorf("expected a name at position %d", p.pos)
+	}
+
+	p.skipSpaces()
+	if !p.consume('(') {
+		return QueryFilter{Contains: []string{name}}, nil
+	}
+
+	switch strings.ToLower(name) {
+	case "contains", "exact":
+		var names []string
+		for !p.consume(')') {
+			if len(names) > 0 && !p.consume(',') {
+				return QueryFilter{}, fmt.Errorf("expected ',' or ')' at position %d", p.pos)
+			}
+			arg := p.parseName()
+			if arg == "" {
+				return QueryFilter{}, fmt.Errorf("expected a component name at position %d", p.pos)
+			}
+			names = append(names, arg)
+			p.skipSpaces()
+		}
+		if len(names) == 0 {
+			return QueryFilter{}, fmt.Errorf("%s needs at least one component", name)
+		}
+		if strings.ToLower(name) == "exact" {
+			return QueryFilter{Exact: names}, nil
+		}
+		return QueryFilter{Contains: names}, nil
+
+	case "and", "or", "not":
+		var operands []QueryFilter
+		for !p.consume(')') {
+			if len(operands) > 0 && !p.consume(',') {
+				return QueryFilter{}, fmt.Errorf("expected ',' or ')' at position %d", p.pos)
+			}
+			operand, err := p.parseExpr()
+			if err != nil {
+				return QueryFilter{}, err
+			}
+			operands = append(operands, operand)
+			p.skipSpaces()
+		}
+		switch strings.ToLower(name) {
+		case "not":
+			if len(operands) != 1 {
+				return QueryFilter{}, errors.New("not needs exactly one filter")
+			}
+			return QueryFilter{Not: &operands[0]}, nil
+		case "or":
+			if len(operands) == 0 {
+				return QueryFilter{}, errors.New("or needs at least one filter")
+			}
+			return QueryFilter{Or: operands}, nil
+		default:
+			if len(operands) == 0 {
+				return QueryFilter{}, errors.New("and needs at least one filter")
+			}
+			return QueryFilter{And: operands}, nil
+		}
+
+	default:
+		return QueryFilter{}, fmt.Errorf("unknown function %q", name)
+	}
+}
+
+func (p *queryParser) parseName() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := rune(p.input[p.pos])
+		if c == '(' || c == ')' || c == ',' || unicode.IsSpace(c) {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *queryParser) consume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQueryFilter(t *testing.T) {
+	testCases := map[string]QueryFilter{
+		"Object": {Contains: []string{"Object"}},
+		"contains(Object, Tween)": {
+			Contains: []string{"Object", "Tween"},
+		},
+		"exact(Object)": {Exact: []string{"Object"}},
+		"and(contains(Object, Tween), not(contains(Player)))": {
+			And: []QueryFilter{
+				{Contains: []string{"Object", "Tween"}},
+				{Not: &QueryFilter{Contains: []string{"Player"}}},
+			},
+		},
+		" or( Wall ,Ramp ) ": {
+			Or: []QueryFilter{
+				{Contains: []string{"Wall"}},
+				{Contains: []string{"Ramp"}},
+			},
+		},
+	}
+
+	for expr, expected := range testCases {
+		t.Run(expr, func(t *testing.T) {
+			actual, err := ParseQueryFilter(expr)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+
+			reparsed, err := ParseQueryFilter(actual.String())
+			require.NoError(t, err)
+			assert.Equal(t, expected, reparsed, "String should round trip")
+		})
+	}
+}
+
+func TestParseQueryFilter_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"and()",
+		"not(Player, Wall)",
+		"contains()",
+		"contains(not(Player))",
+		"xor(Player)",
+		"and(Player",
+		"Player)",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseQueryFilter(expr)
+			assert.Error(t, err)
+		})
+	}
+}
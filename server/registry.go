@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/component"
+)
+
+// componentRegistry resolves component names to the component types discovered in the program.
+//
+// Types used by the world's archetypes take precedence over the other types created with
+// [donburi.NewComponentType], so that a name only needs to be unique within its group.
+type componentRegistry struct {
+	world  map[string][]component.IComponentType
+	global map[string][]component.IComponentType
+}
+
+func newComponentRegistry(world donburi.World) *componentRegistry {
+	var worldTypes []component.IComponentType
+	for _, arch := range world.Archetypes() {
+		worldTypes = append(worldTypes, arch.ComponentTypes()...)
+	}
+	return &componentRegistry{
+		world:  groupByName(worldTypes),
+		global: groupByName(donburi.AllComponentTypes()),
+	}
+}
+
+// lookup returns the component type with the given name.
+func (r *componentRegistry) lookup(name string) (component.IComponentType, error) {
+	types := r.world[name]
+	if len(types) == 0 {
+		types = r.global[name]
+	}
+	switch len(types) {
+	case 0:
+		return nil, errorWithStatus(http.StatusBadRequest, fmt.Sprintf("Unknown component %q", name))
+	case 1:
+		return types[0], nil
+	default:
+		return nil, errorWithStatus(http.StatusBadRequest, fmt.Sprintf("Ambiguous component %q", name))
+	}
+}
+
+// lookupAll returns the component types with the given names.
+func (r *componentRegistry) lookupAll(names []string) ([]component.IComponentType, error) {
+	resolved := make([]component.IComponentType, 0, len(names))
+	for _, name := range names {
+		componentType, err := r.lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, componentType)
+	}
+	return resolved, nil
+}
+
+// groupByName groups distinct component types by name.
+// Distinct component types can share a name, e.g. unnamed tags.
+func groupByName(componentTypes []component.IComponentType) map[string][]component.IComponentType {
+	types := make(map[string][]component.IComponentType)
+	seen := make(map[component.ComponentTypeId]bool)
+	for _, componentType := range componentTypes {
+		if seen[componentType.Id()] {
+			continue
+		}
+		seen[componentType.Id()] = true
+		types[componentType.Name()] = append(types[componentType.Name()], componentType)
+	}
+	return types
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// req: DELETE /entities/3/components/Tween
+// resp: {"entity": {...}}
+func (s *Server) removeComponentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("entity_id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid entity ID", http.StatusBadRequest)
+		return
+	}
+
+	componentName := r.PathValue("component_name")
+	if err := s.policy.checkEditable(componentName, nil, ""); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var response GetEntityResponse
+	err = s.execute(r.Context(), func() error {
+		entry := s.store.GetEntry(uint32(id))
+		if entry == nil {
+			return errorWithStatus(http.StatusNotFound, "Entity not found")
+		}
+
+		componentTypes := entry.Archetype().ComponentTypes()
+		if len(componentTypes) == 1 && componentTypes[0].Name() == componentName {
+			return errorWithStatus(http.StatusBadRequest, "An entity must have at least one component")
+		}
+		found := false
+		for _, componentType := range componentTypes {
+			if componentType.Name() == componentName {
+				entry.RemoveComponent(componentType)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errorWithStatus(http.StatusNotFound, "Component not found")
+		}
+
+		response = GetEntityResponse{Entity: entityFromEntry(entry, s.codecs)}
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
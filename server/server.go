@@ -2,9 +2,17 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"runtime/debug"
+	"strconv"
 	"time"
 
 	"github.com/yohamta/donburi"
@@ -14,73 +22,287 @@ type Store interface {
 	GetWorld() donburi.World
 	GetEntry(id uint32) *donburi.Entry
 	GetEntries() map[uint32]*donburi.Entry
+	AddEntry(entry *donburi.Entry)
+	RemoveEntry(id uint32)
+}
+
+// Executor runs work on the game loop, see [loop.Queue].
+type Executor interface {
+	Do(ctx context.Context, fn func()) error
 }
 
 type Server struct {
-	store      Store
+	store    Store
+	executor Executor
+	history  *history
+	loop     LoopController
+	codecs   *Codecs
+	policy   editPolicy
+	log      *log.Logger
+	// name is the name of the world of the store, see [Server.AddWorld].
+	name     string
+	worlds   *worlds
+	watchers *watchers
+	// ctx is cancelled when the server shuts down, or when its world is removed,
+	// which ends the event streams and the polling of the world.
+	ctx        context.Context
+	cancel     context.CancelFunc
 	httpServer *http.Server
+	listener   net.Listener
+	// discoveryFile is removed on shutdown, if set.
+	discoveryFile string
 }
 
 type Config struct {
+	// Addr is the address to listen on, [DefaultAddr] if empty. With port 0, e.g. ":0",
+	// a free port is chosen, see [Server.Addr] and DiscoveryFile.
+	// Addresses such as "unix:///path/to.sock" listen on a unix socket, see [SocketPath].
 	Addr string
+	// DiscoveryFile is the file the address clients connect to is written to, if not empty.
+	// It is removed when the server is shut down, see [ReadDiscoveryFile].
+	DiscoveryFile string
+	// Executor is used to access the world in sync with the game loop.
+	// If nil, handlers access the world directly from the request goroutine.
+	Executor Executor
+	// HistorySize is the number of field edits that can be undone, [DefaultHistorySize] if zero.
+	HistorySize int
+	// Loop is used to pause and step the game loop. If nil, the loop cannot be controlled.
+	Loop LoopController
+	// World is the name of the world of the store, [DefaultWorld] if empty.
+	// It is served under /worlds/{world}/ as well as at the root, see [Server.AddWorld].
+	World string
+	// Codecs encode and decode the values of specific types, [NewCodecs] if nil.
+	Codecs *Codecs
+	// ReadOnly rejects the requests that modify the world or its history with 403 Forbidden.
+	// The game loop can still be paused, stepped and resumed, since this does not modify the world.
+	ReadOnly bool
+	// EditAllow lists the component names, or field paths prefixed by the component name such as "Player.Health",
+	// that may be edited, if not empty. The fields they hold may be edited too.
+	EditAllow []string
+	// EditDeny lists the components and fields that may not be edited, like EditAllow, even if they are allowed.
+	// Snapshots cannot be restored while edits are restricted by either list.
+	EditDeny []string
+	// Logger receives the server logs, prefixed with "[server]". If nil, the standard logger is used.
+	Logger *log.Logger
+	// Token is the shared secret clients must send in the Authorization header, as "Bearer <token>".
+	// If empty, requests are not authenticated, and addresses without a host, e.g. ":8080", only listen on the loopback interface.
+	Token string
+	// Handlers are additional handlers served next to the editor routes, by pattern, see [http.ServeMux].
+	Handlers map[string]http.Handler
 }
 
-func Start(store Store, cfg Config) (server *Server, err error) {
-	log := log.New(log.Writer(), "[server] ", log.LstdFlags)
+// executeTimeout is how long a request waits for the game loop to pick up its work.
+const executeTimeout = 5 * time.Second
+
+// DefaultAddr is the address the server listens on if [Config.Addr] is empty.
+const DefaultAddr = ":8080"
 
-	server = &Server{
-		store: store,
+// Start starts listening on the address of the config, and serves the world of the store in the background.
+// It returns an error if the address cannot be bound, e.g. because it is already in use.
+func Start(store Store, cfg Config) (*Server, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.Default()
 	}
+	log := log.New(logger.Writer(), "[server] ", logger.Flags())
+
+	server := &Server{
+		store:    store,
+		executor: cfg.Executor,
+		history:  newHistory(cfg.HistorySize),
+		watchers: newWatchers(),
+		loop:     cfg.Loop,
+		codecs:   cfg.Codecs,
+		policy:   editPolicy{readOnly: cfg.ReadOnly, allow: cfg.EditAllow, deny: cfg.EditDeny},
+		log:      log,
+		name:     cfg.World,
+	}
+	server.ctx, server.cancel = context.WithCancel(context.Background())
+	if server.name == "" {
+		server.name = DefaultWorld
+	}
+	if server.codecs == nil {
+		server.codecs = NewCodecs()
+	}
+	server.worlds = newWorlds(server)
 
 	handler := http.NewServeMux()
 
 	handler.HandleFunc("/healthcheck", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
-	handler.HandleFunc("/", handlePanic(server.listArchetypesHandler))
-	handler.HandleFunc("/entities", handlePanic(server.listEntitiesHandler))
-	handler.HandleFunc("/entities/{id}", handlePanic(server.getEntityHandler))
-	handler.HandleFunc("/entities/{id}/components", handlePanic(server.getEntityHandler))
-	handler.HandleFunc("/entities/{entity_id}/components/{component_name}",
-		handlePanic(
-			func(w http.ResponseWriter, r *http.Request) {
-				if r.Method == http.MethodGet {
-					server.getComponentHandler(w, r)
-				} else if r.Method == http.MethodPut {
-					server.setComponentHandler(w, r)
-				}
-			},
-		))
+	// Loop control is allowed in read-only mode, see [Config.ReadOnly].
+	handler.HandleFunc("/loop", handlePanic(server.getLoopHandler))
+	handler.HandleFunc("/loop/pause", handlePanic(server.pauseLoopHandler))
+	handler.HandleFunc("/loop/resume", handlePanic(server.resumeLoopHandler))
+	handler.HandleFunc("/loop/step", handlePanic(server.stepLoopHandler))
+	handler.HandleFunc("/worlds", handlePanic(server.listWorldsHandler))
+	handler.HandleFunc("/worlds/{world}/", handlePanic(server.worldHandler))
+	// The routes outside of /worlds/{world}/ access the world the server was started with.
+	handler.Handle("/", server.routes())
+	for pattern, h := range cfg.Handlers {
+		handler.Handle(pattern, handlePanic(h.ServeHTTP))
+	}
+
+	addr := cfg.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	// Without a token, anyone who can reach the server can edit the world, so it is only reachable locally by default.
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" && cfg.Token == "" {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+	listener, err := listen(addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", addr, err)
+	}
+	server.listener = listener
 
 	s := &http.Server{
-		Addr:           ":8080",
-		Handler:        handler,
+		Handler:        requireToken(cfg.Token, handler),
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	if cfg.Addr != "" {
-		s.Addr = cfg.Addr
-	}
+	// Cancel long-lived requests such as event streams, so shutdown does not wait for them.
+	s.BaseContext = func(net.Listener) context.Context { return server.ctx }
+	s.RegisterOnShutdown(server.cancel)
 
 	server.httpServer = s
 
-	log.Println("Starting editor server on ", s.Addr)
+	if cfg.DiscoveryFile != "" {
+		if err := writeDiscoveryFile(cfg.DiscoveryFile, server.dialAddr()); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("writing discovery file: %w", err)
+		}
+		server.discoveryFile = cfg.DiscoveryFile
+	}
+
+	log.Println("Starting editor server on ", server.Addr())
 	go func() {
-		err = s.ListenAndServe()
-		if err != nil {
-			log.Println("[server] serving http: ", err)
+		err := s.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Println("serving http: ", err)
 		}
 	}()
 
 	return server, nil
 }
 
+// Addr returns the address the server listens on, e.g. with the port chosen by the system if [Config.Addr] has port 0.
+// The addresses of unix sockets start with "unix://", like in the config.
+func (s *Server) Addr() string {
+	if addr, ok := s.listener.Addr().(*net.UnixAddr); ok {
+		return unixScheme + addr.Name
+	}
+	return s.listener.Addr().String()
+}
+
+// dialAddr returns the address clients connect to, which is the loopback address if the server listens on all interfaces.
+func (s *Server) dialAddr() string {
+	addr, ok := s.listener.Addr().(*net.TCPAddr)
+	if !ok || !addr.IP.IsUnspecified() {
+		return s.Addr()
+	}
+	return net.JoinHostPort("localhost", strconv.Itoa(addr.Port))
+}
+
 func (s *Server) Stop() {
-	log.Println("Stopping editor server")
-	if err := s.httpServer.Shutdown(context.Background()); err != nil {
-		log.Fatalf("Could not gracefully shutdown the server: %v\n", err)
+	if err := s.Shutdown(context.Background()); err != nil {
+		s.log.Fatalf("Could not gracefully shutdown the server: %v\n", err)
+	}
+}
+
+// Shutdown stops the server, waiting for the requests in progress until the context is done.
+// Event streams are closed right away.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.log.Println("Stopping editor server")
+	if s.discoveryFile != "" {
+		if err := os.Remove(s.discoveryFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			s.log.Println("removing discovery file: ", err)
+		}
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+// routes returns the handler of the routes accessing the world of the server.
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", handlePanic(s.listArchetypesHandler))
+	mux.HandleFunc("/events", handlePanic(s.eventsHandler))
+	mux.HandleFunc("/query", handlePanic(s.queryHandler))
+	mux.HandleFunc("/history", handlePanic(s.getHistoryHandler))
+	mux.HandleFunc("/history/undo", handlePanic(s.mutating(s.undoHandler)))
+	mux.HandleFunc("/history/redo", handlePanic(s.mutating(s.redoHandler)))
+	mux.HandleFunc("/snapshot/diff", handlePanic(s.diffSnapshotHandler))
+	mux.HandleFunc("/snapshot", handlePanic(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				s.getSnapshotHandler(w, r)
+			case http.MethodPost:
+				s.mutating(s.restoreSnapshotHandler)(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		},
+	))
+	mux.HandleFunc("/entities", handlePanic(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				s.listEntitiesHandler(w, r)
+			case http.MethodPost:
+				s.mutating(s.createEntityHandler)(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		},
+	))
+	mux.HandleFunc("/entities/{id}", handlePanic(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				s.getEntityHandler(w, r)
+			case http.MethodDelete:
+				s.mutating(s.deleteEntityHandler)(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		},
+	))
+	mux.HandleFunc("/entities/{id}/components", handlePanic(s.getEntityHandler))
+	mux.HandleFunc("/entities/{entity_id}/components/{component_name}",
+		handlePanic(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					s.getComponentHandler(w, r)
+				case http.MethodPut:
+					s.mutating(s.setComponentHandler)(w, r)
+				case http.MethodPost:
+					s.mutating(s.addComponentHandler)(w, r)
+				case http.MethodDelete:
+					s.mutating(s.removeComponentHandler)(w, r)
+				case http.MethodPatch:
+					s.mutating(s.modifyCollectionHandler)(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			},
+		))
+	return mux
+}
+
+// mutating rejects the request if the server is read-only.
+func (s *Server) mutating(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.policy.readOnly {
+			http.Error(w, "Editor is read-only", http.StatusForbidden)
+			return
+		}
+		next(w, r)
 	}
 }
 
@@ -96,3 +318,71 @@ func handlePanic(next http.HandlerFunc) http.HandlerFunc {
 		next(w, r)
 	}
 }
+
+// requireToken rejects the requests that do not carry the token, unless the token is empty.
+// The health check is left open, as it does not expose the world.
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := []byte(r.Header.Get("Authorization"))
+		if r.URL.Path != "/healthcheck" && subtle.ConstantTimeCompare(authorization, expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusError is an error that is reported to the client with the given HTTP status code.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+func errorWithStatus(code int, msg string) error {
+	return &statusError{code: code, msg: msg}
+}
+
+// execute runs fn on the game loop and returns its error.
+func (s *Server) execute(ctx context.Context, fn func() error) error {
+	if s.executor == nil {
+		return fn()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, executeTimeout)
+	defer cancel()
+
+	var fnErr error
+	err := s.executor.Do(ctx, func() {
+		fnErr = fn()
+	})
+	if err != nil {
+		return errorWithStatus(http.StatusServiceUnavailable, "Game loop did not process the request in time")
+	}
+	return fnErr
+}
+
+// decodeBody decodes the JSON request body into v.
+// Numbers are kept as [json.Number], so that large integers do not lose precision.
+func decodeBody(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		http.Error(w, statusErr.msg, statusErr.code)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
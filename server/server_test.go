@@ -1,18 +1,29 @@
 package server_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"github.com/thefishhat/tamago/client"
 	"github.com/thefishhat/tamago/inspector"
+	"github.com/thefishhat/tamago/loop"
 	"github.com/thefishhat/tamago/server"
 	"github.com/thefishhat/tamago/store"
 	"github.com/yohamta/donburi"
@@ -32,6 +43,9 @@ type ServerSuite struct {
 	server *server.Server
 	insp   *inspector.Inspector
 	st     *store.Store
+	// queue runs the requests, and the changes the tests make while the server is running, on the game loop.
+	queue *loop.Queue
+	stop  chan struct{}
 }
 
 func (s *ServerSuite) SetupTest() {
@@ -39,10 +53,27 @@ func (s *ServerSuite) SetupTest() {
 	s.st = store.NewStore(s.ecs)
 
 	var err error
-	s.insp, err = inspector.Start(s.st)
+	s.insp, err = inspector.Start(s.st, inspector.Config{})
 	require.NoError(s.T(), err)
 
-	s.server, err = server.Start(s.st, testCfg)
+	queue, stop := loop.NewQueue(), make(chan struct{})
+	s.queue, s.stop = queue, stop
+	go func() {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				queue.Flush()
+			}
+		}
+	}()
+
+	cfg := testCfg
+	cfg.Executor = s.queue
+	s.server, err = server.Start(s.st, cfg)
 	require.NoError(s.T(), err)
 
 	err = waitForHealthyServer()
@@ -51,17 +82,25 @@ func (s *ServerSuite) SetupTest() {
 
 func (s *ServerSuite) TearDownTest() {
 	s.server.Stop()
+	close(s.stop)
 	s.insp.Stop()
 }
 
+// Update runs fn on the game loop, so that it does not race with the requests.
+func (s *ServerSuite) Update(fn func()) {
+	require.NoError(s.T(), s.queue.Do(context.Background(), fn))
+}
+
 func (s *ServerSuite) AddComponents(components ...component.IComponentType) []donburi.Entity {
 	var result []donburi.Entity
-	for _, c := range components {
-		entity := s.ecs.World.Create(c)
-		result = append(result, entity)
-	}
+	s.Update(func() {
+		for _, c := range components {
+			entity := s.ecs.World.Create(c)
+			result = append(result, entity)
+		}
 
-	s.insp.IntrospectECS()
+		s.insp.IntrospectECS()
+	})
 	return result
 }
 
@@ -84,12 +123,7 @@ func (s *ServerSuite) TestListArchetypes() {
 		Archetypes: []server.ArchetypeSummary{
 			{
 				EntityCount: 1,
-				Components: []server.ComponentSummary{
-					{
-						Name: mockComponent.Name(),
-						Type: mockComponent.Typ().Name(),
-					},
-				},
+				Tags:        []string{mockComponent.Name()},
 			},
 		},
 	}, actualResp, "response should match expected")
@@ -120,14 +154,10 @@ func (s *ServerSuite) TestListEntities() {
 			{
 				Id:   "1",
 				Name: entry.String(),
+				Tags: []string{mockComponent.Name()},
 				Archetype: server.ArchetypeSummary{
 					EntityCount: 1,
-					Components: []server.ComponentSummary{
-						{
-							Name: mockComponent.Name(),
-							Type: mockComponent.Typ().Name(),
-						},
-					},
+					Tags:        []string{mockComponent.Name()},
 				},
 			},
 		},
@@ -136,6 +166,73 @@ func (s *ServerSuite) TestListEntities() {
 	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
 }
 
+func (s *ServerSuite) TestListEntitiesFiltered() {
+	type Person struct {
+		Name string
+	}
+	personComponent := donburi.NewComponentType[Person]()
+	personComponent.SetName("MyPersonComponent")
+	playerTag := donburi.NewTag("MyPlayerTag")
+	s.ecs.World.Create(personComponent)
+	player := s.ecs.World.Create(personComponent, playerTag)
+	s.insp.IntrospectECS()
+
+	resp, err := http.Get("http://" + testCfg.Addr + "/entities?tag=MyPlayerTag&component=MyPersonComponent")
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	var actualResp server.ListEntitiesResponse
+	err = json.NewDecoder(resp.Body).Decode(&actualResp)
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), actualResp.Entities, 1)
+	assert.Equal(s.T(), fmt.Sprintf("%d", player.Id()), actualResp.Entities[0].Id)
+	assert.Equal(s.T(), []string{playerTag.Name()}, actualResp.Entities[0].Tags)
+}
+
+func (s *ServerSuite) TestQuery() {
+	type Position struct {
+		X, Y float64
+	}
+	type Velocity struct {
+		X, Y float64
+	}
+	positionComponent := donburi.NewComponentType[Position]()
+	positionComponent.SetName("MyPositionComponent")
+	velocityComponent := donburi.NewComponentType[Velocity]()
+	velocityComponent.SetName("MyVelocityComponent")
+	playerTag := donburi.NewTag("MyQueriedPlayerTag")
+	s.ecs.World.Create(positionComponent)
+	moving := s.ecs.World.Create(positionComponent, velocityComponent)
+	s.ecs.World.Create(positionComponent, velocityComponent, playerTag)
+	s.insp.IntrospectECS()
+
+	queryFilter, err := server.ParseQueryFilter("and(MyPositionComponent, MyVelocityComponent, not(MyQueriedPlayerTag))")
+	require.NoError(s.T(), err)
+	b, err := json.Marshal(queryFilter)
+	require.NoError(s.T(), err)
+
+	resp, err := http.Get("http://" + testCfg.Addr + "/query?filter=" + url.QueryEscape(string(b)))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	var actualResp server.ListEntitiesResponse
+	err = json.NewDecoder(resp.Body).Decode(&actualResp)
+	require.NoError(s.T(), err)
+
+	require.Len(s.T(), actualResp.Entities, 1)
+	assert.Equal(s.T(), fmt.Sprintf("%d", moving.Id()), actualResp.Entities[0].Id)
+}
+
+func (s *ServerSuite) TestQueryUnknownComponent() {
+	resp, err := http.Get("http://" + testCfg.Addr + "/query?filter=" + url.QueryEscape(`{"contains": ["Unknown"]}`))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
 func (s *ServerSuite) TestGetEntity() {
 	mockComponent := donburi.NewComponentType[MockComponent]()
 	mockComponent.SetName("MyTestComponent")
@@ -159,14 +256,10 @@ func (s *ServerSuite) TestGetEntity() {
 			EntitySummary: server.EntitySummary{
 				Id:   fmt.Sprintf("%d", entry.Id()),
 				Name: entry.String(),
+				Tags: []string{mockComponent.Name()},
 				Archetype: server.ArchetypeSummary{
 					EntityCount: 1,
-					Components: []server.ComponentSummary{
-						{
-							Name: mockComponent.Name(),
-							Type: mockComponent.Typ().Name(),
-						},
-					},
+					Tags:        []string{mockComponent.Name()},
 				},
 			},
 			Components: []server.Component{
@@ -238,7 +331,11 @@ func (s *ServerSuite) TestGetComponent() {
 		Value: map[string]interface{}{
 			"Name": "string",
 		},
-		Type: server.ComponentTypeObject,
+		Type:  server.ComponentTypeObject,
+		Field: server.FieldInfo{Type: "server_test.Person", Kind: "struct", Settable: true, Exported: true},
+		Fields: map[string]server.FieldInfo{
+			"Name": server.FieldInfo{Type: "string", Kind: "string", Settable: true, Exported: true},
+		},
 	}, actualResp, "response should match expected")
 
 	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
@@ -269,11 +366,68 @@ func (s *ServerSuite) TestGetComponentField() {
 	assert.Equal(s.T(), server.ComponentResponse{
 		Value: "\"donburi\"",
 		Type:  server.ComponentTypePrimitive,
+		Field: server.FieldInfo{Type: "string", Kind: "string", Settable: true, Exported: true},
 	}, actualResp, "response should match expected")
 
 	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
 }
 
+func (s *ServerSuite) TestGetComponentDepth() {
+	type Address struct {
+		City string
+	}
+	type Person struct {
+		Name    string
+		Address *Address
+	}
+	mockComponent := donburi.NewComponentType[Person]()
+	mockComponent.SetName("MyPersonComponent")
+	entities := s.AddComponents(mockComponent)
+	require.Len(s.T(), entities, 1)
+	entity := entities[0]
+	mockComponent.SetValue(s.ecs.World.Entry(entity), Person{Name: "donburi", Address: &Address{City: "Tokyo"}})
+	s.insp.IntrospectECS()
+
+	url := "http://" + testCfg.Addr + fmt.Sprintf("/entities/%d/components/%s?depth=", entity.Id(), mockComponent.Name())
+	resp, err := http.Get(url + "-1")
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	var actualResp server.ComponentResponse
+	err = json.NewDecoder(resp.Body).Decode(&actualResp)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), server.ComponentResponse{
+		Value: map[string]interface{}{
+			"Name":    "donburi",
+			"Address": map[string]interface{}{"City": "Tokyo"},
+		},
+		Type:  server.ComponentTypeObject,
+		Field: server.FieldInfo{Type: "server_test.Person", Kind: "struct", Settable: true, Exported: true},
+		Fields: map[string]server.FieldInfo{
+			"Name":    server.FieldInfo{Type: "string", Kind: "string", Settable: true, Exported: true},
+			"Address": {Type: "*server_test.Address", Kind: "ptr", Nullable: true, Settable: true, Exported: true},
+		},
+	}, actualResp)
+
+	entityResp, err := http.Get("http://" + testCfg.Addr + fmt.Sprintf("/entities/%d?depth=2", entity.Id()))
+	require.NoError(s.T(), err)
+	defer entityResp.Body.Close()
+
+	var actualEntity server.GetEntityResponse
+	err = json.NewDecoder(entityResp.Body).Decode(&actualEntity)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), actualEntity.Entity.Components, 1)
+	assert.Equal(s.T(), map[string]interface{}{
+		"Name":    "donburi",
+		"Address": map[string]interface{}{"City": "string"},
+	}, actualEntity.Entity.Components[0].Value)
+
+	invalidResp, err := http.Get(url + "-2")
+	require.NoError(s.T(), err)
+	defer invalidResp.Body.Close()
+	assert.Equal(s.T(), http.StatusBadRequest, invalidResp.StatusCode)
+}
+
 func (s *ServerSuite) TestSetComponentField() {
 	type Person struct {
 		Name string
@@ -305,10 +459,1058 @@ func (s *ServerSuite) TestSetComponentField() {
 	assert.Equal(s.T(), "tamago", v.Name)
 }
 
+func (s *ServerSuite) TestUndoRedo() {
+	type Person struct {
+		Name string
+	}
+	mockComponent := donburi.NewComponentType[Person]()
+	mockComponent.SetName("MyUndoPersonComponent")
+	entities := s.AddComponents(mockComponent)
+	require.Len(s.T(), entities, 1)
+	entry := s.ecs.World.Entry(entities[0])
+	mockComponent.SetValue(entry, Person{Name: "donburi"})
+
+	b, err := json.Marshal(server.SetComponentRequest{Value: "tamago"})
+	require.NoError(s.T(), err)
+	req, err := http.NewRequest(http.MethodPut, "http://"+testCfg.Addr+fmt.Sprintf("/entities/%d/components/%s?field=Name", entry.Id(), mockComponent.Name()), bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	post := func(path string) (*http.Response, server.HistoryEntry) {
+		resp, err := http.Post("http://"+testCfg.Addr+path, "application/json", nil)
+		require.NoError(s.T(), err)
+		defer resp.Body.Close()
+		var historyEntry server.HistoryEntry
+		json.NewDecoder(resp.Body).Decode(&historyEntry)
+		return resp, historyEntry
+	}
+
+	resp, undone := post("/history/undo")
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(s.T(), server.HistoryEntry{
+		Id:        1,
+		EntityId:  "1",
+		Component: mockComponent.Name(),
+		Field:     "Name",
+		From:      "donburi",
+		To:        "tamago",
+		Undone:    true,
+	}, undone)
+	assert.Equal(s.T(), "donburi", mockComponent.Get(entry).Name)
+
+	resp, _ = post("/history/undo")
+	assert.Equal(s.T(), http.StatusConflict, resp.StatusCode, "there should be nothing left to undo")
+
+	resp, _ = post("/history/redo")
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(s.T(), "tamago", mockComponent.Get(entry).Name)
+
+	resp, err = http.Get("http://" + testCfg.Addr + "/history")
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	var history server.GetHistoryResponse
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), history.Entries, 1)
+	assert.False(s.T(), history.Entries[0].Undone)
+}
+
+func (s *ServerSuite) TestUndoThroughPointer() {
+	type Address struct {
+		City string
+	}
+	type Person struct {
+		Home *Address
+	}
+	mockComponent := donburi.NewComponentType[Person]()
+	mockComponent.SetName("MyUndoAddressComponent")
+	entities := s.AddComponents(mockComponent)
+	require.Len(s.T(), entities, 1)
+	entry := s.ecs.World.Entry(entities[0])
+	shared := &Address{City: "Osaka"}
+	mockComponent.SetValue(entry, Person{Home: shared})
+
+	// The address is set in place, through the pointer.
+	b, err := json.Marshal(server.SetComponentRequest{Value: map[string]interface{}{"Home": map[string]interface{}{"City": "Kyoto"}}})
+	require.NoError(s.T(), err)
+	req, err := http.NewRequest(http.MethodPut, "http://"+testCfg.Addr+fmt.Sprintf("/entities/%d/components/%s", entry.Id(), mockComponent.Name()), bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(s.T(), "Kyoto", shared.City)
+
+	resp, err = http.Post("http://"+testCfg.Addr+"/history/undo", "application/json", nil)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	var undone server.HistoryEntry
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&undone))
+	assert.Equal(s.T(), map[string]interface{}{"Home": map[string]interface{}{"City": "Osaka"}}, undone.From)
+	assert.Equal(s.T(), map[string]interface{}{"Home": map[string]interface{}{"City": "Kyoto"}}, undone.To)
+	assert.Equal(s.T(), "Osaka", shared.City, "undo should restore the value pointed to")
+	assert.Same(s.T(), shared, mockComponent.Get(entry).Home, "undo should keep the pointer")
+
+	resp, err = http.Post("http://"+testCfg.Addr+"/history/redo", "application/json", nil)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(s.T(), "Kyoto", shared.City)
+}
+
+func (s *ServerSuite) TestModifyCollection() {
+	type Inventory struct {
+		Items []string
+	}
+	mockComponent := donburi.NewComponentType[Inventory]()
+	mockComponent.SetName("MyInventoryComponent")
+	entities := s.AddComponents(mockComponent)
+	require.Len(s.T(), entities, 1)
+	entry := s.ecs.World.Entry(entities[0])
+	mockComponent.SetValue(entry, Inventory{Items: []string{"sword"}})
+
+	b, err := json.Marshal(server.ModifyCollectionRequest{Op: server.OpAppend, Value: "bow"})
+	require.NoError(s.T(), err)
+	req, err := http.NewRequest(http.MethodPatch, "http://"+testCfg.Addr+fmt.Sprintf("/entities/%d/components/%s?field=Items", entry.Id(), mockComponent.Name()), bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	var actualResp server.ComponentResponse
+	err = json.NewDecoder(resp.Body).Decode(&actualResp)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), server.ComponentResponse{
+		Value: []interface{}{"string", "string"},
+		Type:  server.ComponentTypeSlice,
+		Field: server.FieldInfo{Type: "[]string", Kind: "slice", Nullable: true, Settable: true, Exported: true},
+		Fields: map[string]server.FieldInfo{
+			"0": server.FieldInfo{Type: "string", Kind: "string", Settable: true, Exported: true},
+			"1": server.FieldInfo{Type: "string", Kind: "string", Settable: true, Exported: true},
+		},
+	}, actualResp)
+	assert.Equal(s.T(), []string{"sword", "bow"}, mockComponent.Get(entry).Items)
+
+	resp, err = http.Post("http://"+testCfg.Addr+"/history/undo", "application/json", nil)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(s.T(), []string{"sword"}, mockComponent.Get(entry).Items, "undo should restore the slice")
+}
+
+func (s *ServerSuite) TestCreateEntity() {
+	type Person struct {
+		Name string
+	}
+	mockComponent := donburi.NewComponentType[Person]()
+	mockComponent.SetName("MyPersonComponent")
+	s.AddComponents(mockComponent)
+
+	b, err := json.Marshal(server.CreateEntityRequest{
+		Components: []string{mockComponent.Name()},
+		Values: map[string]map[string]interface{}{
+			mockComponent.Name(): {"Name": "tamago"},
+		},
+	})
+	require.NoError(s.T(), err)
+
+	resp, err := http.Post("http://"+testCfg.Addr+"/entities", "application/json", bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusCreated, resp.StatusCode)
+
+	var actualResp server.GetEntityResponse
+	err = json.NewDecoder(resp.Body).Decode(&actualResp)
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), "2", actualResp.Entity.Id)
+	entity := s.st.GetEntry(2)
+	require.NotNil(s.T(), entity, "created entity should be in the store")
+	assert.Equal(s.T(), "tamago", mockComponent.Get(entity).Name)
+}
+
+func (s *ServerSuite) TestCreateEntityUnknownComponent() {
+	b, err := json.Marshal(server.CreateEntityRequest{
+		Components: []string{"Unknown"},
+	})
+	require.NoError(s.T(), err)
+
+	resp, err := http.Post("http://"+testCfg.Addr+"/entities", "application/json", bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(s.T(), 0, s.ecs.World.Len())
+}
+
+func (s *ServerSuite) TestDeleteEntity() {
+	mockComponent := donburi.NewComponentType[MockComponent]()
+	mockComponent.SetName("MyTestComponent")
+	entities := s.AddComponents(mockComponent)
+	require.Len(s.T(), entities, 1)
+	entity := entities[0]
+
+	req, err := http.NewRequest(http.MethodDelete, "http://"+testCfg.Addr+fmt.Sprintf("/entities/%d", entity.Id()), nil)
+	require.NoError(s.T(), err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusNoContent, resp.StatusCode)
+	assert.False(s.T(), s.ecs.World.Valid(entity))
+	assert.Nil(s.T(), s.st.GetEntry(uint32(entity.Id())))
+
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *ServerSuite) TestAddComponent() {
+	type Person struct {
+		Name string
+	}
+	mockComponent := donburi.NewComponentType[MockComponent]()
+	mockComponent.SetName("MyTestComponent")
+	personComponent := donburi.NewComponentType[Person]()
+	personComponent.SetName("MyAddedPersonComponent")
+	entities := s.AddComponents(mockComponent)
+	require.Len(s.T(), entities, 1)
+	entity := entities[0]
+
+	b, err := json.Marshal(server.AddComponentRequest{
+		Values: map[string]interface{}{"Name": "tamago"},
+	})
+	require.NoError(s.T(), err)
+	url := "http://" + testCfg.Addr + fmt.Sprintf("/entities/%d/components/%s", entity.Id(), personComponent.Name())
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusCreated, resp.StatusCode)
+	entry := s.ecs.World.Entry(entity)
+	require.True(s.T(), entry.HasComponent(personComponent))
+	assert.Equal(s.T(), "tamago", personComponent.Get(entry).Name)
+
+	resp, err = http.Post(url, "application/json", nil)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	assert.Equal(s.T(), http.StatusConflict, resp.StatusCode)
+}
+
+func (s *ServerSuite) TestRemoveComponent() {
+	type Person struct {
+		Name string
+	}
+	mockComponent := donburi.NewComponentType[MockComponent]()
+	mockComponent.SetName("MyTestComponent")
+	personComponent := donburi.NewComponentType[Person]()
+	personComponent.SetName("MyRemovedPersonComponent")
+	entity := s.ecs.World.Create(mockComponent, personComponent)
+	s.insp.IntrospectECS()
+
+	remove := func(componentName string) *http.Response {
+		req, err := http.NewRequest(http.MethodDelete, "http://"+testCfg.Addr+fmt.Sprintf("/entities/%d/components/%s", entity.Id(), componentName), nil)
+		require.NoError(s.T(), err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(s.T(), err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := remove(personComponent.Name())
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	entry := s.ecs.World.Entry(entity)
+	assert.False(s.T(), entry.HasComponent(personComponent))
+	assert.True(s.T(), entry.HasComponent(mockComponent))
+
+	resp = remove(personComponent.Name())
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+
+	resp = remove(mockComponent.Name())
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "last component should not be removable")
+}
+
+func (s *ServerSuite) TestSnapshot() {
+	type Position struct {
+		X, Y float64
+		Path []int
+	}
+	positionComponent := donburi.NewComponentType[Position]()
+	positionComponent.SetName("MySnapshotPositionComponent")
+	playerTag := donburi.NewTag("MySnapshotPlayerTag")
+	entity := s.ecs.World.Create(positionComponent, playerTag)
+	positionComponent.SetValue(s.ecs.World.Entry(entity), Position{X: 1, Y: 2, Path: []int{3, 4}})
+	s.insp.IntrospectECS()
+
+	resp, err := http.Get("http://" + testCfg.Addr + "/snapshot")
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	var snapshot server.Snapshot
+	err = json.NewDecoder(resp.Body).Decode(&snapshot)
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), server.Snapshot{
+		Version: server.SnapshotVersion,
+		Entities: []server.SnapshotEntity{
+			{
+				Id:        "1",
+				Archetype: []string{positionComponent.Name(), playerTag.Name()},
+				Components: map[string]interface{}{
+					positionComponent.Name(): map[string]interface{}{
+						"X":    float64(1),
+						"Y":    float64(2),
+						"Path": []interface{}{float64(3), float64(4)},
+					},
+				},
+			},
+		},
+	}, snapshot)
+
+	// Change the world, then restore it from the snapshot.
+	positionComponent.Get(s.ecs.World.Entry(entity)).X = 100
+	s.ecs.World.Create(playerTag)
+	s.insp.IntrospectECS()
+
+	b, err := json.Marshal(snapshot)
+	require.NoError(s.T(), err)
+	resp, err = http.Post("http://"+testCfg.Addr+"/snapshot", "application/json", bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	var restoreResp server.RestoreSnapshotResponse
+	err = json.NewDecoder(resp.Body).Decode(&restoreResp)
+	require.NoError(s.T(), err)
+
+	require.Equal(s.T(), 1, s.ecs.World.Len())
+	require.Len(s.T(), restoreResp.Entities, 1)
+	id, err := strconv.Atoi(restoreResp.Entities["1"])
+	require.NoError(s.T(), err)
+	entry := s.st.GetEntry(uint32(id))
+	require.NotNil(s.T(), entry, "restored entity should be in the store")
+	assert.Len(s.T(), s.st.GetEntries(), 1)
+	assert.True(s.T(), entry.HasComponent(playerTag))
+	assert.Equal(s.T(), Position{X: 1, Y: 2, Path: []int{3, 4}}, *positionComponent.Get(entry))
+}
+
+func (s *ServerSuite) TestSnapshotRestoreInvalid() {
+	type Position struct {
+		X, Y float64
+	}
+	positionComponent := donburi.NewComponentType[Position]()
+	positionComponent.SetName("MyInvalidSnapshotPositionComponent")
+	s.AddComponents(positionComponent)
+
+	b, err := json.Marshal(server.Snapshot{
+		Version: server.SnapshotVersion,
+		Entities: []server.SnapshotEntity{
+			{
+				Id:        "1",
+				Archetype: []string{positionComponent.Name()},
+				Components: map[string]interface{}{
+					positionComponent.Name(): map[string]interface{}{"X": "not a number"},
+				},
+			},
+		},
+	})
+	require.NoError(s.T(), err)
+
+	resp, err := http.Post("http://"+testCfg.Addr+"/snapshot", "application/json", bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(s.T(), 1, s.ecs.World.Len(), "world should be untouched")
+}
+
+func (s *ServerSuite) TestDiffSnapshotWithWorld() {
+	type Position struct {
+		X, Y float64
+	}
+	positionComponent := donburi.NewComponentType[Position]()
+	positionComponent.SetName("MyDiffPositionComponent")
+	entities := s.AddComponents(positionComponent)
+	require.Len(s.T(), entities, 1)
+
+	resp, err := http.Get("http://" + testCfg.Addr + "/snapshot")
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	var snapshot server.Snapshot
+	err = json.NewDecoder(resp.Body).Decode(&snapshot)
+	require.NoError(s.T(), err)
+
+	positionComponent.Get(s.ecs.World.Entry(entities[0])).X = 5
+
+	b, err := json.Marshal(server.DiffSnapshotRequest{From: snapshot})
+	require.NoError(s.T(), err)
+	resp, err = http.Post("http://"+testCfg.Addr+"/snapshot/diff", "application/json", bytes.NewReader(b))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	var diff server.SnapshotDiff
+	err = json.NewDecoder(resp.Body).Decode(&diff)
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), server.SnapshotDiff{
+		Added:   []server.SnapshotEntity{},
+		Removed: []server.SnapshotEntity{},
+		Changed: []server.EntityDiff{
+			{
+				Id: "1",
+				Fields: []server.FieldChange{
+					{Component: positionComponent.Name(), Field: "X", Type: server.ChangeChanged, From: float64(0), To: float64(5)},
+				},
+			},
+		},
+	}, diff)
+}
+
+func (s *ServerSuite) TestEvents() {
+	type Person struct {
+		Name string
+	}
+	mockComponent := donburi.NewComponentType[Person]()
+	mockComponent.SetName("MyPersonComponent")
+	entities := s.AddComponents(mockComponent)
+	require.Len(s.T(), entities, 1)
+	entity := entities[0]
+	s.Update(func() {
+		mockComponent.SetValue(s.ecs.World.Entry(entity), Person{Name: "donburi"})
+	})
+
+	resp, err := http.Get("http://" + testCfg.Addr + fmt.Sprintf("/events?entity=%d&component=%s&field=Name", entity.Id(), mockComponent.Name()))
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(s.T(), "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+	nextEvent := func() server.Event {
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var event server.Event
+			require.NoError(s.T(), json.Unmarshal([]byte(data), &event))
+			return event
+		}
+		s.T().Fatal("stream ended")
+		return server.Event{}
+	}
+
+	entityID := fmt.Sprintf("%d", entity.Id())
+	assert.Equal(s.T(), server.Event{
+		Type:      server.EventFieldChanged,
+		EntityId:  entityID,
+		Component: mockComponent.Name(),
+		Field:     "Name",
+		Value:     "\"donburi\"",
+		ValueType: server.ComponentTypePrimitive,
+	}, nextEvent(), "current value should be sent first")
+
+	s.Update(func() {
+		mockComponent.SetValue(s.ecs.World.Entry(entity), Person{Name: "tamago"})
+	})
+	assert.Equal(s.T(), server.Event{
+		Type:      server.EventFieldChanged,
+		EntityId:  entityID,
+		Component: mockComponent.Name(),
+		Field:     "Name",
+		Value:     "\"tamago\"",
+		ValueType: server.ComponentTypePrimitive,
+	}, nextEvent())
+
+	created := s.AddComponents(mockComponent)
+	assert.Equal(s.T(), server.Event{
+		Type:     server.EventEntityCreated,
+		EntityId: fmt.Sprintf("%d", created[0].Id()),
+	}, nextEvent())
+}
+
+func (s *ServerSuite) TestEventsSubscribers() {
+	type Person struct {
+		Name string
+	}
+	mockComponent := donburi.NewComponentType[Person]()
+	mockComponent.SetName("MyPersonComponent")
+	entities := s.AddComponents(mockComponent)
+	require.Len(s.T(), entities, 1)
+	entity := entities[0]
+
+	// Subscriptions polled together each get their own events, and keep getting them when others leave.
+	subscribe := func() (func() server.Event, func()) {
+		resp, err := http.Get("http://" + testCfg.Addr + fmt.Sprintf("/events?entity=%d&component=%s&field=Name", entity.Id(), mockComponent.Name()))
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), http.StatusOK, resp.StatusCode)
+		scanner := bufio.NewScanner(resp.Body)
+		return func() server.Event {
+			for scanner.Scan() {
+				data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+				if !ok {
+					continue
+				}
+				var event server.Event
+				require.NoError(s.T(), json.Unmarshal([]byte(data), &event))
+				return event
+			}
+			s.T().Fatal("stream ended")
+			return server.Event{}
+		}, func() { resp.Body.Close() }
+	}
+	first, closeFirst := subscribe()
+	second, closeSecond := subscribe()
+	defer closeSecond()
+	assert.Equal(s.T(), "\"\"", first().Value)
+	assert.Equal(s.T(), "\"\"", second().Value)
+
+	s.Update(func() {
+		mockComponent.SetValue(s.ecs.World.Entry(entity), Person{Name: "donburi"})
+	})
+	assert.Equal(s.T(), "\"donburi\"", first().Value)
+	assert.Equal(s.T(), "\"donburi\"", second().Value)
+
+	closeFirst()
+	s.Update(func() {
+		mockComponent.SetValue(s.ecs.World.Entry(entity), Person{Name: "tamago"})
+	})
+	assert.Equal(s.T(), "\"tamago\"", second().Value)
+}
+
+func (s *ServerSuite) TestEventsEntityNotFound() {
+	resp, err := http.Get("http://" + testCfg.Addr + "/events?entity=42")
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *ServerSuite) TestLoopNotAvailable() {
+	resp, err := http.Get("http://" + testCfg.Addr + "/loop")
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	assert.Equal(s.T(), http.StatusNotImplemented, resp.StatusCode)
+}
+
 func TestServerSuite(t *testing.T) {
 	suite.Run(t, new(ServerSuite))
 }
 
+func TestSetComponentFieldOnGameLoop(t *testing.T) {
+	type Person struct {
+		Name string
+	}
+	w := ecs.NewECS(donburi.NewWorld())
+	st := store.NewStore(w)
+	insp, err := inspector.Start(st, inspector.Config{})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	queue := loop.NewQueue()
+	w.AddSystem(queue.System)
+	srv, err := server.Start(st, server.Config{Addr: testCfg.Addr, Executor: queue})
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	mockComponent := donburi.NewComponentType[Person]()
+	mockComponent.SetName("MyPersonComponent")
+	entity := w.World.Create(mockComponent)
+	insp.IntrospectECS()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.Update()
+			}
+		}
+	}()
+	require.NoError(t, waitForHealthyServer())
+
+	b, err := json.Marshal(server.SetComponentRequest{Value: "tamago"})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, "http://"+testCfg.Addr+fmt.Sprintf("/entities/%d/components/%s?field=Name", entity.Id(), mockComponent.Name()), bytes.NewReader(b))
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var actualResp server.ComponentResponse
+	err = json.NewDecoder(resp.Body).Decode(&actualResp)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, server.ComponentResponse{
+		Value: "\"tamago\"",
+		Type:  server.ComponentTypePrimitive,
+		Field: server.FieldInfo{Type: "string", Kind: "string", Settable: true, Exported: true},
+	}, actualResp, "response should hold the applied value")
+}
+
+func TestLoopControl(t *testing.T) {
+	w := ecs.NewECS(donburi.NewWorld())
+	st := store.NewStore(w)
+	controller := loop.NewController()
+	srv, err := server.Start(st, server.Config{Addr: testCfg.Addr, Loop: controller})
+	require.NoError(t, err)
+	defer srv.Stop()
+	require.NoError(t, waitForHealthyServer())
+
+	post := func(path string) server.LoopStatus {
+		resp, err := http.Post("http://"+testCfg.Addr+path, "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var status server.LoopStatus
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		return status
+	}
+
+	assert.Equal(t, server.LoopStatus{Paused: true}, post("/loop/pause"))
+	assert.False(t, controller.Update(w))
+
+	assert.Equal(t, server.LoopStatus{Paused: true}, post("/loop/step?frames=2"))
+	for i := 0; i < 5; i++ {
+		controller.Update(w)
+	}
+
+	resp, err := http.Get("http://" + testCfg.Addr + "/loop")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var status server.LoopStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.Equal(t, server.LoopStatus{Paused: true, Frame: 2}, status)
+
+	assert.Equal(t, server.LoopStatus{Paused: false, Frame: 2}, post("/loop/resume"))
+
+	resp, err = http.Post("http://"+testCfg.Addr+"/loop/step?frames=0", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestReadOnly(t *testing.T) {
+	type Person struct {
+		Name string
+	}
+	w := ecs.NewECS(donburi.NewWorld())
+	personComponent := donburi.NewComponentType[Person]()
+	personComponent.SetName("Person")
+	entity := w.World.Create(personComponent)
+	st := store.NewStore(w)
+	insp, err := inspector.Start(st, inspector.Config{})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	srv, err := server.Start(st, server.Config{
+		Addr:     testCfg.Addr,
+		Loop:     loop.NewController(),
+		ReadOnly: true,
+		Handlers: map[string]http.Handler{
+			"/debug/hello": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "hello")
+			}),
+		},
+	})
+	require.NoError(t, err)
+	defer srv.Stop()
+	require.NoError(t, waitForHealthyServer())
+
+	do := func(method string, path string, body string) *http.Response {
+		req, err := http.NewRequest(method, "http://"+testCfg.Addr+path, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	componentPath := fmt.Sprintf("/entities/%d/components/Person", entity.Id())
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, componentPath, "").StatusCode)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPut, componentPath, `{"value": "tamago"}`).StatusCode)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodDelete, componentPath, "").StatusCode)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/entities", `{}`).StatusCode)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/history/undo", "").StatusCode)
+	// Controlling the loop does not modify the world, so it stays allowed.
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/loop/pause", "").StatusCode)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/loop/step?frames=2", "").StatusCode)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/loop/resume", "").StatusCode)
+
+	resp, err := http.Get("http://" + testCfg.Addr + "/debug/hello")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+}
+
+func TestEditPolicy(t *testing.T) {
+	type Player struct {
+		Name   string
+		Health int
+		Tags   []string
+	}
+	w := ecs.NewECS(donburi.NewWorld())
+	playerComponent := donburi.NewComponentType[Player](Player{Tags: []string{"hero"}})
+	playerComponent.SetName("Player")
+	type Item struct {
+		Count int
+	}
+	inventory := map[string]*Item{"sword": {Count: 1}, "bow": {Count: 1}, "shield": {Count: 1}}
+	inventoryComponent := donburi.NewComponentType[map[string]*Item](inventory)
+	inventoryComponent.SetName("Inventory")
+	scores := map[int]*Item{1: {Count: 10}, 2: {Count: 20}}
+	scoresComponent := donburi.NewComponentType[map[int]*Item](scores)
+	scoresComponent.SetName("Scores")
+	entity := w.World.Create(playerComponent, inventoryComponent, scoresComponent)
+	st := store.NewStore(w)
+	insp, err := inspector.Start(st, inspector.Config{})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	srv, err := server.Start(st, server.Config{
+		Addr:      testCfg.Addr,
+		EditAllow: []string{"Player", "Inventory[sword]", "Inventory[bow]", "Scores"},
+		EditDeny:  []string{"Player.Health", "Player.Tags[0]", "Inventory[bow]", "Scores[1]"},
+	})
+	require.NoError(t, err)
+	defer srv.Stop()
+	require.NoError(t, waitForHealthyServer())
+
+	entityPath := fmt.Sprintf("http://%s/entities/%d/components/", testCfg.Addr, entity.Id())
+	componentPath := entityPath + "Player"
+	putComponent := func(component string, field string, body string) int {
+		req, err := http.NewRequest(http.MethodPut, entityPath+component+"?field="+url.QueryEscape(field), strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	put := func(field string, body string) int {
+		return putComponent("Player", field, body)
+	}
+
+	assert.Equal(t, http.StatusOK, put("Name", `{"value": "tamago"}`))
+	assert.Equal(t, http.StatusForbidden, put("Health", `{"value": 0}`))
+	assert.Equal(t, http.StatusForbidden, put("Tags[0]", `{"value": "villain"}`))
+	// Setting the whole component would set the denied fields too.
+	assert.Equal(t, http.StatusForbidden, put("", `{"value": {"Name": "egg"}}`))
+	assert.Equal(t, "tamago", playerComponent.Get(w.World.Entry(entity)).Name)
+	assert.Equal(t, []string{"hero"}, playerComponent.Get(w.World.Entry(entity)).Tags)
+
+	resp, err := http.Get(componentPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var response server.ComponentResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.True(t, response.Field.Locked)
+	assert.False(t, response.Fields["Name"].Locked)
+	assert.True(t, response.Fields["Health"].Locked)
+	assert.True(t, response.Fields["Tags"].Locked)
+
+	// Map entries are matched whatever the spelling of their key.
+	assert.Equal(t, http.StatusOK, putComponent("Inventory", "sword.Count", `{"value": 2}`))
+	assert.Equal(t, http.StatusOK, putComponent("Inventory", "[sword].Count", `{"value": 3}`))
+	assert.Equal(t, http.StatusForbidden, putComponent("Inventory", "bow.Count", `{"value": 0}`))
+	assert.Equal(t, http.StatusForbidden, putComponent("Inventory", "[bow].Count", `{"value": 0}`))
+	assert.Equal(t, http.StatusForbidden, putComponent("Inventory", "shield.Count", `{"value": 0}`))
+	assert.Equal(t, http.StatusForbidden, putComponent("Scores", "1.Count", `{"value": 0}`))
+	assert.Equal(t, http.StatusForbidden, putComponent("Scores", "[01].Count", `{"value": 0}`))
+	assert.Equal(t, http.StatusOK, putComponent("Scores", "[02].Count", `{"value": 0}`))
+	assert.Equal(t, 3, inventory["sword"].Count)
+	assert.Equal(t, 1, inventory["bow"].Count)
+	assert.Equal(t, 1, inventory["shield"].Count)
+	assert.Equal(t, 10, scores[1].Count)
+	assert.Equal(t, 0, scores[2].Count)
+
+	resp, err = http.Get(entityPath + "Inventory")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	response = server.ComponentResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.False(t, response.Fields["sword"].Locked)
+	assert.True(t, response.Fields["bow"].Locked)
+	assert.True(t, response.Fields["shield"].Locked)
+
+	resp, err = http.Post("http://"+testCfg.Addr+"/snapshot", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestWorlds(t *testing.T) {
+	level := ecs.NewECS(donburi.NewWorld())
+	levelStore := store.NewStore(level)
+	levelInsp, err := inspector.Start(levelStore, inspector.Config{})
+	require.NoError(t, err)
+	defer levelInsp.Stop()
+
+	menu := ecs.NewECS(donburi.NewWorld())
+	menuStore := store.NewStore(menu)
+	menuInsp, err := inspector.Start(menuStore, inspector.Config{})
+	require.NoError(t, err)
+	defer menuInsp.Stop()
+
+	buttonComponent := donburi.NewComponentType[MockComponent]()
+	buttonComponent.SetName("Button")
+	button := menu.World.Create(buttonComponent)
+	level.World.Create(donburi.NewComponentType[MockComponent]())
+	level.World.Create(donburi.NewComponentType[MockComponent]())
+
+	srv, err := server.Start(levelStore, server.Config{Addr: testCfg.Addr, World: "level"})
+	require.NoError(t, err)
+	defer srv.Stop()
+	require.NoError(t, waitForHealthyServer())
+
+	require.NoError(t, srv.AddWorld("menu", menuStore, nil))
+	assert.EqualError(t, srv.AddWorld("menu", menuStore, nil), `world "menu" already exists`)
+
+	get := func(path string, response interface{}) int {
+		resp, err := http.Get("http://" + testCfg.Addr + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(response))
+		}
+		return resp.StatusCode
+	}
+
+	var worlds server.ListWorldsResponse
+	require.Equal(t, http.StatusOK, get("/worlds", &worlds))
+	assert.Equal(t, []server.WorldSummary{
+		{Name: "level", EntityCount: 2},
+		{Name: "menu", EntityCount: 1},
+	}, worlds.Worlds)
+
+	var entities server.ListEntitiesResponse
+	require.Equal(t, http.StatusOK, get("/worlds/menu/entities", &entities))
+	require.Len(t, entities.Entities, 1)
+	assert.Equal(t, strconv.Itoa(int(button.Id())), entities.Entities[0].Id)
+
+	var entity server.GetEntityResponse
+	path := fmt.Sprintf("/worlds/menu/entities/%d", button.Id())
+	require.Equal(t, http.StatusOK, get(path, &entity))
+	assert.Equal(t, "Button", entity.Entity.Components[0].Name)
+
+	// The routes outside of /worlds access the world the server was started with.
+	require.Equal(t, http.StatusOK, get("/entities", &entities))
+	assert.Len(t, entities.Entities, 2)
+	require.Equal(t, http.StatusOK, get("/worlds/level/entities", &entities))
+	assert.Len(t, entities.Entities, 2)
+
+	assert.Equal(t, http.StatusNotFound, get("/worlds/overlay/entities", &entities))
+
+	require.NoError(t, srv.RemoveWorld("menu"))
+	assert.Equal(t, http.StatusNotFound, get("/worlds/menu/entities", &entities))
+	assert.Error(t, srv.RemoveWorld("level"))
+}
+
+func TestRemoveWorldClosesEvents(t *testing.T) {
+	st := store.NewStore(ecs.NewECS(donburi.NewWorld()))
+	srv, err := server.Start(st, server.Config{Addr: "127.0.0.1:0"})
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	menu := ecs.NewECS(donburi.NewWorld())
+	queue, stop := loop.NewQueue(), make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				queue.Flush()
+			}
+		}
+	}()
+	require.NoError(t, srv.AddWorld("menu", store.NewStore(menu), queue))
+
+	resp, err := http.Get("http://" + srv.Addr() + "/worlds/menu/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The game stops updating the removed world, so the stream must not wait for it.
+	close(stop)
+	require.NoError(t, srv.RemoveWorld("menu"))
+
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("the event stream of the removed world is still open")
+	}
+}
+
+func TestStartEphemeralPort(t *testing.T) {
+	st := store.NewStore(ecs.NewECS(donburi.NewWorld()))
+	discoveryFile := filepath.Join(t.TempDir(), "tamago", "server.addr")
+
+	srv, err := server.Start(st, server.Config{Addr: "127.0.0.1:0", DiscoveryFile: discoveryFile})
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	host, port, err := net.SplitHostPort(srv.Addr())
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+	assert.NotEqual(t, "0", port)
+
+	addr, err := server.ReadDiscoveryFile(discoveryFile)
+	require.NoError(t, err)
+	assert.Equal(t, srv.Addr(), addr)
+
+	resp, err := http.Get("http://" + addr + "/healthcheck")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The address is already in use.
+	_, err = server.Start(st, server.Config{Addr: srv.Addr()})
+	assert.ErrorContains(t, err, "address already in use")
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	_, err = os.Stat(discoveryFile)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestStartUnixSocket(t *testing.T) {
+	type Score struct {
+		Points int
+	}
+	w := ecs.NewECS(donburi.NewWorld())
+	scoreComponent := donburi.NewComponentType[Score]()
+	scoreComponent.SetName("Score")
+	entity := w.World.Create(scoreComponent)
+	st := store.NewStore(w)
+	insp, err := inspector.Start(st, inspector.Config{})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	socket := filepath.Join(t.TempDir(), "tamago.sock")
+	srv, err := server.Start(st, server.Config{Addr: "unix://" + socket})
+	require.NoError(t, err)
+	assert.Equal(t, "unix://"+socket, srv.Addr())
+
+	c := client.NewClient(srv.Addr())
+	entities, err := c.GetEntities()
+	require.NoError(t, err)
+	assert.Len(t, entities.Entities, 1)
+	require.NoError(t, c.SetComponent(strconv.Itoa(int(entity.Id())), "Score", "Points", 10))
+	assert.Equal(t, 10, scoreComponent.Get(w.World.Entry(entity)).Points)
+
+	// The socket of a server that was not shut down is replaced.
+	require.NoError(t, srv.Shutdown(context.Background()))
+	_, err = os.Stat(socket)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	srv, err = server.Start(st, server.Config{Addr: "unix://" + socket})
+	require.NoError(t, err)
+	defer srv.Stop()
+	_, err = c.GetEntities()
+	assert.NoError(t, err)
+}
+
+func TestToken(t *testing.T) {
+	type Score struct {
+		Points int
+	}
+	w := ecs.NewECS(donburi.NewWorld())
+	scoreComponent := donburi.NewComponentType[Score]()
+	scoreComponent.SetName("Score")
+	entity := w.World.Create(scoreComponent)
+	st := store.NewStore(w)
+	insp, err := inspector.Start(st, inspector.Config{})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	srv, err := server.Start(st, server.Config{Addr: "127.0.0.1:0", Token: "secret"})
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	get := func(path string, authorization string) int {
+		req, err := http.NewRequest(http.MethodGet, "http://"+srv.Addr()+path, nil)
+		require.NoError(t, err)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, get("/healthcheck", ""))
+	assert.Equal(t, http.StatusUnauthorized, get("/entities", ""))
+	assert.Equal(t, http.StatusUnauthorized, get("/entities", "Bearer wrong"))
+	assert.Equal(t, http.StatusOK, get("/entities", "Bearer secret"))
+
+	c := client.NewClient(srv.Addr())
+	_, err = c.ListWorlds()
+	assert.ErrorContains(t, err, "Unauthorized")
+	c.Token = "secret"
+	worlds, err := c.ListWorlds()
+	require.NoError(t, err)
+	assert.Len(t, worlds.Worlds, 1)
+
+	// Edits are authenticated too.
+	require.NoError(t, c.SetComponent(strconv.Itoa(int(entity.Id())), "Score", "Points", 10))
+	assert.Equal(t, 10, scoreComponent.Get(w.World.Entry(entity)).Points)
+
+	// With a wrong token, reads fail instead of returning an empty world.
+	id := strconv.Itoa(int(entity.Id()))
+	c.Token = "wrong"
+	_, err = c.GetEntities()
+	assert.ErrorContains(t, err, "Unauthorized")
+	_, err = c.FilterEntities(server.EntityFilter{Components: []string{"Score"}})
+	assert.ErrorContains(t, err, "Unauthorized")
+	_, err = c.GetEntity(id)
+	assert.ErrorContains(t, err, "Unauthorized")
+	_, err = c.GetComponent(id, "Score", "")
+	assert.ErrorContains(t, err, "Unauthorized")
+	_, err = c.GetComponent(id, "Score", "Points")
+	assert.ErrorContains(t, err, "Unauthorized")
+}
+
+func TestDefaultAddrWithoutToken(t *testing.T) {
+	st := store.NewStore(ecs.NewECS(donburi.NewWorld()))
+	srv, err := server.Start(st, server.Config{Addr: ":0"})
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	host, _, err := net.SplitHostPort(srv.Addr())
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+}
+
 func waitForHealthyServer() error {
 	// exponential backoff
 	// 50ms, 100ms, 200ms, 400ms, 800ms, 1600ms, 3200ms, 6400ms, 12800ms, 25600ms
@@ -324,3 +1526,54 @@ func waitForHealthyServer() error {
 
 	return fmt.Errorf("server unhealthy after 10 retries")
 }
+
+func TestClientSetComponent(t *testing.T) {
+	type Stats struct {
+		Level int
+		Name  string
+		Alive bool
+	}
+	w := ecs.NewECS(donburi.NewWorld())
+	statsComponent := donburi.NewComponentType[Stats]()
+	statsComponent.SetName("Stats")
+	entity := w.World.Create(statsComponent)
+	st := store.NewStore(w)
+	insp, err := inspector.Start(st, inspector.Config{})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	srv, err := server.Start(st, server.Config{Addr: "127.0.0.1:0"})
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	c := client.NewClient(srv.Addr())
+	id := strconv.Itoa(int(entity.Id()))
+	require.NoError(t, c.SetComponent(id, "Stats", "Level", int64(5)))
+	require.NoError(t, c.SetComponent(id, "Stats", "Name", "tamago"))
+	require.NoError(t, c.SetComponent(id, "Stats", "Alive", true))
+	assert.Equal(t, Stats{Level: 5, Name: "tamago", Alive: true}, *statsComponent.Get(w.World.Entry(entity)))
+}
+
+func TestClientGetComponentEscapesFieldPath(t *testing.T) {
+	type Inventory struct {
+		Items map[string]int
+	}
+	w := ecs.NewECS(donburi.NewWorld())
+	inventoryComponent := donburi.NewComponentType[Inventory](Inventory{Items: map[string]int{"salt & pepper #1+2 %": 3}})
+	inventoryComponent.SetName("Inventory")
+	entity := w.World.Create(inventoryComponent)
+	st := store.NewStore(w)
+	insp, err := inspector.Start(st, inspector.Config{})
+	require.NoError(t, err)
+	defer insp.Stop()
+
+	srv, err := server.Start(st, server.Config{Addr: "127.0.0.1:0"})
+	require.NoError(t, err)
+	defer srv.Stop()
+
+	c := client.NewClient(srv.Addr())
+	id := strconv.Itoa(int(entity.Id()))
+	resp, err := c.GetComponent(id, "Inventory", server.IndexFieldPath("Items", "salt & pepper #1+2 %"))
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, resp.Value)
+}
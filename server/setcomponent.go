@@ -13,7 +13,10 @@ type SetComponentRequest struct {
 
 // req: /entities/3/components/PlayerData?field=IgnorePlatform
 // body: {"value": true}
-// resp: 200, etc.
+// resp: {"value": true, "type": "primitive"}
+//
+// The response holds the value of the field after the edit has been applied. It is encoded
+// as in the component handler, so a value holding pointers costs a walk of the whole world.
 func (s *Server) setComponentHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("entity_id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -22,36 +25,61 @@ func (s *Server) setComponentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entry := s.store.GetEntry(uint32(id))
-	if entry == nil {
-		http.Error(w, "Entity not found", http.StatusNotFound)
-		return
-	}
-
 	fieldPath := r.URL.Query().Get("field")
 	componentName := r.PathValue("component_name")
 
-	var component reflect.Value
-	for _, componentType := range entry.Archetype().ComponentTypes() {
-		if componentType.Name() == componentName {
-			ptr := entry.Component(componentType)
-			component = reflect.Indirect(reflect.NewAt(componentType.Typ(), ptr))
-		}
-	}
-
 	// Read the request body and decode into SetComponentRequest
 	var req SetComponentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeBody(r, &req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// Pass the value from the request body into SetField
-	err = SetField(component, fieldPath, req.Value)
+	var response ComponentResponse
+	err = s.execute(r.Context(), func() error {
+		entry := s.store.GetEntry(uint32(id))
+		if entry == nil {
+			return errorWithStatus(http.StatusNotFound, "Entity not found")
+		}
+
+		component, ok := findComponent(entry, componentName)
+		if !ok {
+			return errorWithStatus(http.StatusNotFound, "Component not found")
+		}
+		// Map keys are compared as parsed into the key type, so the policy is checked with the component.
+		if err := s.policy.checkEditable(componentName, component.Type(), fieldPath); err != nil {
+			return err
+		}
+
+		// Keep the previous value, so that the edit can be undone.
+		field, err := findField(component, fieldPath)
+		if err != nil {
+			return err
+		}
+		var previous reflect.Value
+		if field.CanSet() {
+			previous = deepCopyValue(field)
+		}
+
+		// Pass the value from the request body into SetField
+		err = setField(component, fieldPath, req.Value, s.codecs)
+		if err != nil {
+			return err
+		}
+		s.history.record(uint32(id), componentName, fieldPath, previous, deepCopyValue(field))
+
+		response, err = getComponentResponse(component, fieldPath, DefaultDepth, newLazyLinkIndex(s.store).owner(entry, componentName), s.codecs)
+		s.policy.lockFields(componentName, component.Type(), fieldPath, &response)
+		return err
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
 }
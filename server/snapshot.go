@@ -0,0 +1,201 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"unsafe"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/component"
+)
+
+// SnapshotVersion is the version of the [Snapshot] format produced by the server.
+const SnapshotVersion = 1
+
+// Snapshot is the full state of a world: every entity, its archetype and its components' exported fields.
+type Snapshot struct {
+	Version  int              `json:"version"`
+	Entities []SnapshotEntity `json:"entities"`
+}
+
+type SnapshotEntity struct {
+	Id string `json:"id"`
+	// Archetype holds the names of all the entity's components, including tags.
+	Archetype []string `json:"archetype"`
+	// Components holds the exported fields of the entity's data components, keyed by component name.
+	Components map[string]interface{} `json:"components"`
+}
+
+type RestoreSnapshotResponse struct {
+	// Entities maps the entity IDs in the snapshot to the IDs of the restored entities.
+	Entities map[string]string `json:"entities"`
+}
+
+// req: GET /snapshot
+// resp: {"version": 1, "entities": [...]}
+func (s *Server) getSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	var response Snapshot
+	err := s.execute(r.Context(), func() error {
+		response = takeSnapshot(s.store.GetWorld(), s.codecs)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// req: POST /snapshot
+// body: {"version": 1, "entities": [...]}
+// resp: {"entities": {"1": "4", ...}}
+//
+// All entities in the world are replaced by the ones in the snapshot.
+// Restored entities get new IDs, and unexported fields keep their default values.
+func (s *Server) restoreSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	// Restoring replaces every component, including the locked ones.
+	if s.policy.restricted() {
+		http.Error(w, "Restoring snapshots is disabled while edits are restricted", http.StatusForbidden)
+		return
+	}
+	var snapshot Snapshot
+	if err := decodeBody(r, &snapshot); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if snapshot.Version != SnapshotVersion {
+		http.Error(w, fmt.Sprintf("Unsupported snapshot version %d, expected %d", snapshot.Version, SnapshotVersion), http.StatusBadRequest)
+		return
+	}
+
+	var response RestoreSnapshotResponse
+	err := s.execute(r.Context(), func() error {
+		entities, err := s.restoreSnapshot(snapshot)
+		if err != nil {
+			return err
+		}
+		response.Entities = entities
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func takeSnapshot(world donburi.World, codecs *Codecs) Snapshot {
+	snapshot := Snapshot{
+		Version:  SnapshotVersion,
+		Entities: []SnapshotEntity{},
+	}
+	for _, arch := range world.Archetypes() {
+		for _, entity := range arch.Entities() {
+			entry := world.Entry(entity)
+			snapshotEntity := SnapshotEntity{
+				Id:         strconv.FormatUint(uint64(entry.Id()), 10),
+				Components: make(map[string]interface{}),
+			}
+			for _, componentType := range arch.ComponentTypes() {
+				snapshotEntity.Archetype = append(snapshotEntity.Archetype, componentType.Name())
+				if isTag(componentType) {
+					continue
+				}
+				component := reflect.NewAt(componentType.Typ(), entry.Component(componentType)).Elem()
+				snapshotEntity.Components[componentType.Name()] = encodeValue(component, codecs)
+			}
+			snapshot.Entities = append(snapshot.Entities, snapshotEntity)
+		}
+	}
+	slices.SortFunc(snapshot.Entities, func(a, b SnapshotEntity) int {
+		idA, _ := strconv.Atoi(a.Id)
+		idB, _ := strconv.Atoi(b.Id)
+		return cmp.Compare(idA, idB)
+	})
+	return snapshot
+}
+
+// restoredEntity is an entity of a snapshot whose components have been decoded, but not yet added to the world.
+type restoredEntity struct {
+	id             string
+	componentTypes []component.IComponentType
+	components     map[component.IComponentType]unsafe.Pointer
+}
+
+// restoreSnapshot replaces all entities in the world with the ones in the snapshot.
+// The world is left untouched if any of the entities cannot be decoded.
+func (s *Server) restoreSnapshot(snapshot Snapshot) (map[string]string, error) {
+	world := s.store.GetWorld()
+	registry := newComponentRegistry(world)
+
+	restored := make([]restoredEntity, 0, len(snapshot.Entities))
+	for _, snapshotEntity := range snapshot.Entities {
+		if len(snapshotEntity.Archetype) == 0 {
+			return nil, fmt.Errorf("entity %s: an entity must have at least one component", snapshotEntity.Id)
+		}
+		componentTypes, err := registry.lookupAll(snapshotEntity.Archetype)
+		if err != nil {
+			return nil, fmt.Errorf("entity %s: %w", snapshotEntity.Id, err)
+		}
+
+		entity := restoredEntity{
+			id:             snapshotEntity.Id,
+			componentTypes: componentTypes,
+			components:     make(map[component.IComponentType]unsafe.Pointer),
+		}
+		for name, value := range snapshotEntity.Components {
+			i := slices.IndexFunc(componentTypes, func(c component.IComponentType) bool { return c.Name() == name })
+			if i < 0 {
+				return nil, fmt.Errorf("entity %s: component %q is not in the archetype", snapshotEntity.Id, name)
+			}
+			componentType := componentTypes[i]
+			ptr := componentType.New()
+			if err := decodeSnapshotValue(reflect.NewAt(componentType.Typ(), ptr).Elem(), value, s.codecs); err != nil {
+				return nil, fmt.Errorf("entity %s: %s: %w", snapshotEntity.Id, name, err)
+			}
+			entity.components[componentType] = ptr
+		}
+		restored = append(restored, entity)
+	}
+
+	for _, entry := range allEntries(world) {
+		s.removeEntry(entry)
+	}
+
+	ids := make(map[string]string, len(restored))
+	for _, entity := range restored {
+		entry := world.Entry(world.Create(entity.componentTypes...))
+		for componentType, ptr := range entity.components {
+			entry.SetComponent(componentType, ptr)
+		}
+		s.store.AddEntry(entry)
+		ids[entity.id] = strconv.FormatUint(uint64(entry.Id()), 10)
+	}
+	return ids, nil
+}
+
+// allEntries returns the entries of all entities in the world.
+func allEntries(world donburi.World) []*donburi.Entry {
+	var entries []*donburi.Entry
+	for _, arch := range world.Archetypes() {
+		for _, entity := range arch.Entities() {
+			entries = append(entries, world.Entry(entity))
+		}
+	}
+	return entries
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snapshotNode struct {
+	Name     string
+	Next     *snapshotNode
+	Children []snapshotNode
+	Weights  map[int]float64
+	hidden   int
+	Callback func()
+}
+
+func TestEncodeDecodeValue_RoundTrip(t *testing.T) {
+	original := snapshotNode{
+		Name:     "root",
+		Next:     &snapshotNode{Name: "next"},
+		Children: []snapshotNode{{Name: "child"}},
+		Weights:  map[int]float64{1: 0.5, 2: 1.5},
+		hidden:   42,
+		Callback: func() {},
+	}
+
+	// Encoded values go through JSON, the same as a snapshot sent to the server.
+	b, err := json.Marshal(encodeValue(reflect.ValueOf(original), nil))
+	require.NoError(t, err)
+	var encoded interface{}
+	require.NoError(t, json.Unmarshal(b, &encoded))
+
+	var decoded snapshotNode
+	require.NoError(t, decodeSnapshotValue(reflect.ValueOf(&decoded).Elem(), encoded, nil))
+
+	assert.Equal(t, "root", decoded.Name)
+	require.NotNil(t, decoded.Next)
+	assert.Equal(t, "next", decoded.Next.Name)
+	assert.Equal(t, []snapshotNode{{Name: "child"}}, decoded.Children)
+	assert.Equal(t, map[int]float64{1: 0.5, 2: 1.5}, decoded.Weights)
+	assert.Zero(t, decoded.hidden)
+	assert.Nil(t, decoded.Callback)
+}
+
+func TestEncodeValue_Cycle(t *testing.T) {
+	node := snapshotNode{Name: "loop"}
+	node.Next = &node
+
+	encoded := encodeValue(reflect.ValueOf(&node).Elem(), nil)
+
+	assert.Equal(t, map[string]interface{}{
+		"Name":     "loop",
+		"Next":     map[string]interface{}{RefKey: ""},
+		"Children": nil,
+		"Weights":  nil,
+	}, encoded)
+
+	var decoded snapshotNode
+	require.NoError(t, decodeSnapshotValue(reflect.ValueOf(&decoded).Elem(), encoded, nil))
+	assert.Equal(t, "loop", decoded.Name)
+	assert.Same(t, &decoded, decoded.Next, "the cycle should be restored")
+}
+
+func TestEncodeDecodeValue_SharedPointersAndCodecs(t *testing.T) {
+	type Body struct {
+		Bounds image.Rectangle
+	}
+	type Space struct {
+		Bodies []*Body
+		Player *Body
+	}
+	body := &Body{Bounds: image.Rect(0, 0, 10, 20)}
+	original := Space{Bodies: []*Body{body}, Player: body}
+	codecs := NewCodecs()
+
+	b, err := json.Marshal(encodeValue(reflect.ValueOf(&original).Elem(), codecs))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Bodies": [{"Bounds": "(0,0)-(10,20)"}], "Player": {"$ref": "Bodies[0]"}}`, string(b))
+	var encoded interface{}
+	require.NoError(t, json.Unmarshal(b, &encoded))
+
+	var decoded Space
+	require.NoError(t, decodeSnapshotValue(reflect.ValueOf(&decoded).Elem(), encoded, codecs))
+	require.Len(t, decoded.Bodies, 1)
+	assert.Equal(t, image.Rect(0, 0, 10, 20), decoded.Bodies[0].Bounds)
+	assert.Same(t, decoded.Bodies[0], decoded.Player, "shared pointers should stay shared")
+}
+
+func TestDecodeValue_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		target interface{}
+		value  interface{}
+		err    string
+	}{
+		{"unknown field", &snapshotNode{}, map[string]interface{}{"Missing": 1.0}, `unknown field "Missing"`},
+		{"unexported field", &snapshotNode{}, map[string]interface{}{"hidden": 1.0}, `unknown field "hidden"`},
+		{"wrong type", &snapshotNode{}, map[string]interface{}{"Name": 1.0}, "Name: expected a string"},
+		{"not an integer", new(int), 1.5, "1.5 is not an integer"},
+		{"overflow", new(int8), 300.0, "300 overflows int8"},
+		{"negative uint", new(uint), -1.0, "-1 overflows uint"},
+		{"array length", new([2]int), []interface{}{1.0}, "expected 2 elements"},
+		{"map key", new(map[int]bool), map[string]interface{}{"a": true}, `invalid map key "a"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeValue(reflect.ValueOf(tt.target).Elem(), tt.value, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
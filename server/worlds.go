@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultWorld is the name of the world the server is started with, unless [Config.World] is set.
+const DefaultWorld = "default"
+
+type WorldSummary struct {
+	Name        string `json:"name"`
+	EntityCount int    `json:"entity_count"`
+}
+
+type ListWorldsResponse struct {
+	Worlds []WorldSummary `json:"worlds"`
+}
+
+// worlds holds the worlds served by a server, in the order they were added.
+type worlds struct {
+	mu     sync.RWMutex
+	names  []string
+	worlds map[string]*world
+}
+
+type world struct {
+	server  *Server
+	handler http.Handler
+}
+
+func newWorlds(server *Server) *worlds {
+	return &worlds{
+		names: []string{server.name},
+		worlds: map[string]*world{
+			server.name: {server: server, handler: server.routes()},
+		},
+	}
+}
+
+func (w *worlds) get(name string) (*world, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	world, ok := w.worlds[name]
+	return world, ok
+}
+
+// AddWorld serves another world under /worlds/{name}/, with the same routes as the world the server
+// was started with. Its requests are run by the executor, which may be nil, see [Config.Executor].
+// Each world has its own edit history; the game loop controls only apply to the world the server was started with.
+func (s *Server) AddWorld(name string, store Store, executor Executor) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid world name %q", name)
+	}
+
+	worldServer := &Server{
+		store:    store,
+		executor: executor,
+		history:  newHistory(s.history.size),
+		watchers: newWatchers(),
+		codecs:   s.codecs,
+		policy:   s.policy,
+		log:      s.log,
+		name:     name,
+		worlds:   s.worlds,
+	}
+	worldServer.ctx, worldServer.cancel = context.WithCancel(s.ctx)
+
+	s.worlds.mu.Lock()
+	defer s.worlds.mu.Unlock()
+	if _, ok := s.worlds.worlds[name]; ok {
+		worldServer.cancel()
+		return fmt.Errorf("world %q already exists", name)
+	}
+	s.worlds.names = append(s.worlds.names, name)
+	s.worlds.worlds[name] = &world{server: worldServer, handler: worldServer.routes()}
+	return nil
+}
+
+// RemoveWorld stops serving a world added with [Server.AddWorld].
+// Its event streams are closed, so its executor is no longer used once the requests in progress are done.
+func (s *Server) RemoveWorld(name string) error {
+	if name == s.name {
+		return errors.New("cannot remove the world the server was started with")
+	}
+
+	s.worlds.mu.Lock()
+	defer s.worlds.mu.Unlock()
+	world, ok := s.worlds.worlds[name]
+	if !ok {
+		return fmt.Errorf("world %q not found", name)
+	}
+	world.server.cancel()
+	delete(s.worlds.worlds, name)
+	for i, n := range s.worlds.names {
+		if n == name {
+			s.worlds.names = append(s.worlds.names[:i], s.worlds.names[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// listWorldsHandler lists the worlds with the number of entities in their store.
+// The worlds are not accessed, so the worlds that are not being updated are listed too.
+func (s *Server) listWorldsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var response ListWorldsResponse
+	s.worlds.mu.RLock()
+	for _, name := range s.worlds.names {
+		response.Worlds = append(response.Worlds, WorldSummary{
+			Name:        name,
+			EntityCount: len(s.worlds.worlds[name].server.store.GetEntries()),
+		})
+	}
+	s.worlds.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// worldHandler serves the routes of the world under /worlds/{world}/.
+func (s *Server) worldHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("world")
+	world, ok := s.worlds.get(name)
+	if !ok {
+		http.Error(w, "World not found", http.StatusNotFound)
+		return
+	}
+	http.StripPrefix("/worlds/"+name, world.handler).ServeHTTP(w, r)
+}
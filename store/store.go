@@ -1,13 +1,18 @@
 package store
 
 import (
+	"maps"
+	"sync"
+
 	"github.com/yohamta/donburi"
 	"github.com/yohamta/donburi/ecs"
 )
 
 // Store is an in-memory cache for the ECS data.
+// It is safe for concurrent use.
 type Store struct {
 	ecs     *ecs.ECS
+	mu      sync.RWMutex
 	entries map[uint32]*donburi.Entry
 }
 
@@ -26,18 +31,38 @@ func (s *Store) GetWorld() donburi.World {
 
 // GetEntry returns the entry with the given ID from the store.
 func (s *Store) GetEntry(id uint32) *donburi.Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	if entry, ok := s.entries[id]; ok {
 		return entry
 	}
 	return nil
 }
 
-// GetEntries returns all entries in the store.
+// GetEntries returns a copy of all entries in the store.
 func (s *Store) GetEntries() map[uint32]*donburi.Entry {
-	return s.entries
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.entries)
 }
 
 // SetEntries sets the entries in the store.
 func (s *Store) SetEntries(entries map[uint32]*donburi.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.entries = entries
 }
+
+// AddEntry adds or replaces a single entry in the store.
+func (s *Store) AddEntry(entry *donburi.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[uint32(entry.Id())] = entry
+}
+
+// RemoveEntry removes the entry with the given ID from the store.
+func (s *Store) RemoveEntry(id uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+}
@@ -61,3 +61,24 @@ func TestStore_SetEntries(t *testing.T) {
 
 	assert.Equal(t, entries, store.GetEntries())
 }
+
+func TestStore_AddEntry(t *testing.T) {
+	world := donburi.NewWorld()
+	store := NewStore(ecs.NewECS(world))
+	entry := world.Entry(world.Create(donburi.NewTag()))
+
+	store.AddEntry(entry)
+
+	assert.Equal(t, entry, store.GetEntry(uint32(entry.Id())))
+}
+
+func TestStore_RemoveEntry(t *testing.T) {
+	ecs := &ecs.ECS{}
+	store := NewStore(ecs)
+	store.entries[1] = &donburi.Entry{}
+
+	store.RemoveEntry(1)
+
+	assert.Nil(t, store.GetEntry(1))
+	assert.Empty(t, store.GetEntries())
+}